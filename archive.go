@@ -0,0 +1,318 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ulikunitz/xz"
+)
+
+// An Artifact represents digests of a release file, computed while reading it once
+type Artifact struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	SHA512 string `json:"sha512"`
+}
+
+// entryVisitor visits each archive entry during the one decompression pass,
+// reader yields content of the entry only, zip entries come in tar header
+type entryVisitor func(hdr *tar.Header, r io.Reader) error
+
+// An archiveFormat represents archive by file extension, tarball decompresses
+// by decompress, zip is read from its central directory
+type archiveFormat struct {
+	ext        string
+	decompress func(r io.Reader) (io.Reader, error)
+}
+
+func (f *archiveFormat) zip() bool {
+	return f.decompress == nil
+}
+
+var archiveFormats = []archiveFormat{
+	{ext: ".tgz", decompress: gunzip},
+	{ext: ".tar.gz", decompress: gunzip},
+	{ext: ".tbz2", decompress: bunzip2},
+	{ext: ".tar.bz2", decompress: bunzip2},
+	{ext: ".txz", decompress: unxz},
+	{ext: ".tar.xz", decompress: unxz},
+	{ext: ".tar", decompress: func(r io.Reader) (io.Reader, error) { return r, nil }},
+	{ext: ".zip"},
+}
+
+func gunzip(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+func bunzip2(r io.Reader) (io.Reader, error) {
+	return bzip2.NewReader(r), nil
+}
+
+func unxz(r io.Reader) (io.Reader, error) {
+	return xz.NewReader(r)
+}
+
+// archiveFormatOf format of archive by file name
+func archiveFormatOf(name string) (*archiveFormat, error) {
+	for i := range archiveFormats {
+		if strings.HasSuffix(name, archiveFormats[i].ext) {
+			return &archiveFormats[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("archive %s unsupported", name)
+}
+
+// isArchive whether name has a supported archive extension
+func isArchive(name string) bool {
+	_, err := archiveFormatOf(name)
+	return err == nil
+}
+
+// signatureCheck verifies detached signature against data written into it,
+// so that signature hasher shares the read with digests
+type signatureCheck struct {
+	pw   *io.PipeWriter
+	done chan struct{}
+
+	signer *openpgp.Entity
+	err    error
+}
+
+func newSignatureCheck(keyring openpgp.KeyRing, sign io.Reader) *signatureCheck {
+	pr, pw := io.Pipe()
+	c := &signatureCheck{pw: pw, done: make(chan struct{})}
+
+	go func() {
+		defer close(c.done)
+		c.signer, c.err = openpgp.CheckArmoredDetachedSignature(keyring, pr, sign, nil)
+		// keep draining, writer never blocks even if check gave up early
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+
+	return c
+}
+
+func (c *signatureCheck) Write(p []byte) (int, error) {
+	return c.pw.Write(p)
+}
+
+// close end the stream with read error if any, then wait for outcome
+func (c *signatureCheck) close(err error) {
+	_ = c.pw.CloseWithError(err)
+	<-c.done
+
+	if err != nil {
+		c.signer, c.err = nil, err
+	}
+}
+
+// countWriter counts bytes written
+type countWriter int64
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	*w += countWriter(len(p))
+	return len(p), nil
+}
+
+// An archiveScan represents outcome of one pass over an archive
+type archiveScan struct {
+	artifact   Artifact
+	entriesErr error // corrupted archive or visitor failure
+}
+
+// scanArchive read filename once: the raw stream feeds digests and signature
+// check if any, the decompressed stream dispatches entries to visitors.
+// Zip entries are read from the file after the stream, as its directory
+// lies at the end. Error tells the file unreadable, signature outcome stays in sign
+func scanArchive(filename string, sign *signatureCheck, visitors []entryVisitor) (*archiveScan, error) {
+	s := &archiveScan{artifact: Artifact{Name: filepath.Base(filename)}}
+
+	format, err := archiveFormatOf(filename)
+	if err != nil {
+		if sign != nil {
+			sign.close(err)
+		}
+		return nil, err
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		if sign != nil {
+			sign.close(err)
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	h256, h512 := sha256.New(), sha512.New()
+	var size countWriter
+	writers := []io.Writer{h256, h512, &size}
+	if sign != nil {
+		writers = append(writers, sign)
+	}
+	tee := io.TeeReader(f, io.MultiWriter(writers...))
+
+	if len(visitors) > 0 && !format.zip() {
+		s.entriesErr = walkTar(tee, format.decompress, visitors)
+	}
+	// rest of the stream, like gzip trailer or whole file without visitors
+	_, err = io.Copy(io.Discard, tee)
+	if sign != nil {
+		sign.close(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(visitors) > 0 && format.zip() {
+		s.entriesErr = walkZip(f, int64(size), visitors)
+	}
+
+	s.artifact.Size = int64(size)
+	s.artifact.SHA256 = fmt.Sprintf("%x", h256.Sum(nil))
+	s.artifact.SHA512 = fmt.Sprintf("%x", h512.Sum(nil))
+	return s, nil
+}
+
+// walkTar decompress tarball once, each entry goes to all visitors; the
+// decompressed stream is drained after tar end, so trailer like gzip CRC verified
+func walkTar(r io.Reader, decompress func(io.Reader) (io.Reader, error), visitors []entryVisitor) error {
+	dr, err := decompress(r)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(dr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			_, err = io.Copy(io.Discard, dr)
+			return err
+		} else if err != nil {
+			return err
+		}
+
+		if err := visitEntry(hdr, tr, visitors); err != nil {
+			return err
+		}
+	}
+}
+
+// walkZip read zip entries from its central directory, each entry goes to all
+// visitors in tar header, symbolic link target read from its content
+func walkZip(r io.ReaderAt, size int64, visitors []entryVisitor) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if err := visitZipEntry(zf, visitors); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func visitZipEntry(zf *zip.File, visitors []entryVisitor) error {
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var content io.Reader = rc
+	var link string
+	if zf.Mode()&os.ModeSymlink != 0 {
+		target, err := io.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		link = string(target)
+		content = strings.NewReader("")
+	}
+
+	hdr, err := tar.FileInfoHeader(zf.FileInfo(), link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = zf.Name
+	hdr.ModTime = zf.Modified
+
+	if err := visitEntry(hdr, content, visitors); err != nil {
+		return err
+	}
+
+	// checksum of zip entry verified at the end of its content
+	_, err = io.Copy(io.Discard, content)
+	return err
+}
+
+// visitEntry stream entry content to visitors concurrently through pipes,
+// content never buffers as a whole
+func visitEntry(hdr *tar.Header, r io.Reader, visitors []entryVisitor) error {
+	if len(visitors) == 1 {
+		return visitors[0](hdr, r)
+	}
+
+	errs := make([]error, len(visitors))
+	pipes := make([]*io.PipeWriter, len(visitors))
+	writers := make([]io.Writer, len(visitors))
+	done := make(chan struct{}, len(visitors))
+	for i, visit := range visitors {
+		pr, pw := io.Pipe()
+		pipes[i], writers[i] = pw, pw
+
+		go func(i int, visit entryVisitor) {
+			errs[i] = visit(hdr, pr)
+			_, _ = io.Copy(io.Discard, pr)
+			done <- struct{}{}
+		}(i, visit)
+	}
+
+	_, err := io.Copy(io.MultiWriter(writers...), r)
+	for _, pw := range pipes {
+		_ = pw.CloseWithError(err)
+	}
+	for range visitors {
+		<-done
+	}
+
+	if err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
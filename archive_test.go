@@ -0,0 +1,304 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ulikunitz/xz"
+)
+
+// tbz2 tar.bz2 of LICENSE and NOTICE, compress/bzip2 doesn't write
+const tbz2 = "QlpoOTFBWSZTWWgyvQ0AAJL/gcoAIABAAHeAKiXMcGphXgAAAIgIIACShqENNGmgAaPUAwgVRRDI9Q0AAADS+5YqlPWQcMMRCF1PTOyGNdoqWoQlExRcrIrWKg588CMssBLRqTKxRKjOlIyRPIpCFxGY30BNKVqo7ER18HuAcIB3og8PNf2wJfr1q9mRB/F3JFOFCQaDK9DQ"
+
+// tarball plain tar of files, keep order of names
+func tarball(t *testing.T, names []string, files map[string]string) []byte {
+	var hdrs []*tar.Header
+	for _, name := range names {
+		hdrs = append(hdrs, &tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg})
+	}
+
+	return tarHeaders(t, hdrs, files)
+}
+
+// tarHeaders plain tar of entries by headers, bodies taken from files
+func tarHeaders(t *testing.T, hdrs []*tar.Header, files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range hdrs {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[hdr.Name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// tgz gzip tarball of files, keep order of names
+func tgz(t *testing.T, names []string, files map[string]string) []byte {
+	return gzipped(t, tarball(t, names, files))
+}
+
+// tgzHeaders gzip tarball of empty entries by headers
+func tgzHeaders(t *testing.T, hdrs []*tar.Header) []byte {
+	return gzipped(t, tarHeaders(t, hdrs, nil))
+}
+
+// gzipped gzip stream of body
+func gzipped(t *testing.T, body []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(body); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// zipball zip of files, keep order of names
+func zipball(t *testing.T, names []string, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func txz(t *testing.T, names []string, files map[string]string) []byte {
+	var buf bytes.Buffer
+	xw, err := xz.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := xw.Write(tarball(t, names, files)); err != nil {
+		t.Fatal(err)
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestArchiveFormats(t *testing.T) {
+	names := []string{"LICENSE", "NOTICE"}
+	files := map[string]string{"LICENSE": "Apache License", "NOTICE": "Apache APISIX"}
+	bz2, err := base64.StdEncoding.DecodeString(tbz2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		body    []byte
+		wantErr bool
+	}{
+		{name: "pkg-src.tgz", body: tgz(t, names, files)},
+		{name: "pkg.tar.gz", body: tgz(t, names, files)},
+		{name: "pkg.tar.bz2", body: bz2},
+		{name: "pkg.tar.xz", body: txz(t, names, files)},
+		{name: "pkg.tar", body: tarball(t, names, files)},
+		{name: "pkg-src.zip", body: zipball(t, names, files)},
+		{name: "pkg-src.zip", body: tgz(t, names, files), wantErr: true},
+		{name: "pkg.tar.xz", body: tgz(t, names, files), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(filename, tt.body, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			seen := map[string]string{}
+			s, err := scanArchive(filename, nil, []entryVisitor{func(hdr *tar.Header, r io.Reader) error {
+				body, err := io.ReadAll(r)
+				seen[hdr.Name] = string(body)
+				return err
+			}})
+			if err != nil {
+				t.Fatalf("scanArchive() error = %v", err)
+			}
+			if (s.entriesErr != nil) != tt.wantErr {
+				t.Fatalf("entries error = %v, wantErr %v", s.entriesErr, tt.wantErr)
+			}
+			if s.artifact.SHA512 != fmt.Sprintf("%x", sha512.Sum512(tt.body)) {
+				t.Errorf("sha512 mismatch")
+			}
+			if tt.wantErr {
+				return
+			}
+
+			for _, name := range names {
+				if seen[name] != files[name] {
+					t.Errorf("%s = %q, want %q", name, seen[name], files[name])
+				}
+			}
+		})
+	}
+
+	if _, err := scanArchive("pkg.rar", nil, nil); err == nil {
+		t.Error("unsupported archive expect error")
+	}
+}
+
+func TestScanArchive(t *testing.T) {
+	files := map[string]string{"LICENSE": "Apache License", "NOTICE": "Apache APISIX", "README.md": "# APISIX"}
+	names := []string{"LICENSE", "NOTICE", "README.md"}
+	pkg := tgz(t, names, files)
+
+	dir := t.TempDir()
+	signer := newSigner(t, "Zeping Bai")
+	keys := writeRelease(t, dir, "pkg-src.tgz", pkg, signer)
+	filename := filepath.Join(dir, "pkg-src.tgz")
+
+	// gzip trailer holds CRC-32 then size of tarball
+	badCRC := append([]byte{}, pkg...)
+	badCRC[len(badCRC)-8] ^= 0xff
+
+	tests := []struct {
+		name     string
+		body     []byte
+		entries  int
+		wantErr  bool
+		signedBy *openpgp.Entity
+	}{
+		{name: "tgz", body: pkg, entries: len(names), signedBy: signer},
+		{name: "corrupted", body: []byte("not a gzip stream"), wantErr: true},
+		{name: "corrupted trailer", body: badCRC, entries: len(names), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(filename, tt.body, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			keyring, err := readKeyRing(keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			asc, err := os.Open(filename + ".asc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer asc.Close()
+			sign := newSignatureCheck(keyring, asc)
+
+			// two visitors read every entry from the same pass
+			var seen [2]map[string]string
+			var visitors []entryVisitor
+			for i := range seen {
+				m := map[string]string{}
+				seen[i] = m
+				visitors = append(visitors, func(hdr *tar.Header, r io.Reader) error {
+					body, err := io.ReadAll(r)
+					m[hdr.Name] = string(body)
+					return err
+				})
+			}
+
+			s, err := scanArchive(filename, sign, visitors)
+			if err != nil {
+				t.Fatalf("scanArchive() error = %v", err)
+			}
+			if (s.entriesErr != nil) != tt.wantErr {
+				t.Errorf("entries error = %v, wantErr %v", s.entriesErr, tt.wantErr)
+			}
+
+			want := Artifact{
+				Name:   "pkg-src.tgz",
+				Size:   int64(len(tt.body)),
+				SHA256: fmt.Sprintf("%x", sha256.Sum256(tt.body)),
+				SHA512: fmt.Sprintf("%x", sha512.Sum512(tt.body)),
+			}
+			if s.artifact != want {
+				t.Errorf("artifact = %+v, want %+v", s.artifact, want)
+			}
+
+			for i, m := range seen {
+				if len(m) != tt.entries {
+					t.Errorf("visitor %d saw %d entries, want %d", i, len(m), tt.entries)
+				}
+				for name, body := range m {
+					if body != files[name] {
+						t.Errorf("visitor %d %s = %q, want %q", i, name, body, files[name])
+					}
+				}
+			}
+
+			if tt.signedBy != nil && (sign.err != nil || sign.signer.PrimaryKey.KeyId != tt.signedBy.PrimaryKey.KeyId) {
+				t.Errorf("signature error = %v, want signed by %s", sign.err, tt.signedBy.PrimaryKey.KeyIdString())
+			}
+			if tt.signedBy == nil && sign.err == nil {
+				t.Error("signature over other content expect bad")
+			}
+		})
+	}
+}
+
+func TestDist_Verify(t *testing.T) {
+	dir := t.TempDir()
+	pkg := tgz(t, []string{"LICENSE"}, map[string]string{"LICENSE": "Apache License"})
+	keys := writeRelease(t, dir, "apache-apisix-dashboard-2.11.0-src.tgz", pkg, newSigner(t, "Zeping Bai"))
+
+	d := localDist(filepath.Join(dir, "apache-apisix-dashboard-2.11.0-src.tgz"))
+	d.keys = keys
+	d.announcer = "Zeping Bai"
+	d.report = &Report{}
+	d.Verify()
+
+	r := d.Report()
+	if len(r.Artifacts) != 1 || r.Artifacts[0].SHA512 != fmt.Sprintf("%x", sha512.Sum512(pkg)) {
+		t.Errorf("artifacts = %+v", r.Artifacts)
+	}
+
+	var buf bytes.Buffer
+	r.Summary(&buf)
+	want := "apisix-dashboard 2.11.0: 9 ok, 2 bad\n  ❌ layout NOTICE\n  ❌ layout README\n"
+	if buf.String() != want {
+		t.Errorf("Summary() = %q, want %q", buf.String(), want)
+	}
+}
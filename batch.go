@@ -0,0 +1,277 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	batchFile string
+	workers   uint
+)
+
+// A Task represents one candidate to verify in batch
+type Task struct {
+	Project   string `yaml:"project"`
+	Candidate string `yaml:"candidate"`
+	Commit    string `yaml:"commit"`
+	Announcer string `yaml:"announcer"`
+}
+
+// loadTasks load tasks from YAML or CSV file, decided by file extension
+func loadTasks(filename string) ([]Task, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".yaml", ".yml":
+		return yamlTasks(f)
+	case ".csv":
+		return csvTasks(f)
+	default:
+		return nil, fmt.Errorf("unsupported task file type %s", ext)
+	}
+}
+
+func yamlTasks(r io.Reader) ([]Task, error) {
+	var tasks []Task
+	if err := yaml.NewDecoder(r).Decode(&tasks); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// csvTasks columns: project,candidate,commit,announcer, header line is optional
+func csvTasks(r io.Reader) ([]Task, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	for i, rec := range records {
+		if i == 0 && len(rec) > 0 && rec[0] == "project" {
+			continue
+		}
+		if len(rec) < 2 {
+			return nil, fmt.Errorf("line %d: project and candidate required", i+1)
+		}
+
+		task := Task{Project: rec[0], Candidate: rec[1]}
+		if len(rec) > 2 {
+			task.Commit = rec[2]
+		}
+		if len(rec) > 3 {
+			task.Announcer = rec[3]
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// discoverTasks open candidates of all projects under dist, announcer comes
+// from global flag, commit left empty as it differs per candidate
+func discoverTasks() ([]Task, error) {
+	var tasks []Task
+	for _, name := range projectNames {
+		versions, err := dist(name).Candidates()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range versions {
+			tasks = append(tasks, Task{
+				Project:   name,
+				Candidate: v.String(),
+				Announcer: announcer,
+			})
+		}
+	}
+
+	return tasks, nil
+}
+
+// Dist build project dist of task, files download into workspace
+func (t *Task) Dist(ws *Workspace) (*Dist, error) {
+	d := dist(t.Project)
+	if d == nil {
+		return nil, fmt.Errorf("project %s unsupported", t.Project)
+	}
+
+	rc, err := ParseSemver(t.Candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	d.rc = rc
+	d.commit = t.Commit
+	d.announcer = t.Announcer
+	if d.announcer == "" {
+		d.announcer = announcer
+	}
+	d.ws = ws
+	d.dir = filepath.Join(ws.Dir(), d.Package())
+
+	return d, os.MkdirAll(d.dir, 0755)
+}
+
+// verify the same flow as project command: links, GitHub API, fetch then verify
+func (t *Task) verify(d *Dist) {
+	if err := d.ValidAllLinks(); err != nil {
+		return
+	}
+
+	if err := d.ValidGitHubAPI(); err != nil {
+		return
+	}
+
+	if err := d.Fetch(); err != nil {
+		return
+	}
+
+	d.Verify()
+	d.VerifyGit()
+	d.DiffPrevious()
+}
+
+// A Batch verifies tasks concurrently with bounded workers,
+// tasks of the same package share downloads and run one by one
+type Batch struct {
+	tasks   []Task
+	workers int
+	ws      *Workspace
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewBatch batch instance
+func NewBatch(tasks []Task, workers int, ws *Workspace) *Batch {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return &Batch{
+		tasks:   tasks,
+		workers: workers,
+		ws:      ws,
+		locks:   map[string]*sync.Mutex{},
+	}
+}
+
+func (b *Batch) lock(dir string) *sync.Mutex {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l, ok := b.locks[dir]
+	if !ok {
+		l = &sync.Mutex{}
+		b.locks[dir] = l
+	}
+
+	return l
+}
+
+// Run verify all tasks, reports keep the same order as tasks
+func (b *Batch) Run() []*Report {
+	reports := make([]*Report, len(b.tasks))
+	parallel(b.workers, len(b.tasks), func(i int) {
+		reports[i] = b.run(&b.tasks[i])
+	})
+
+	return reports
+}
+
+func (b *Batch) run(t *Task) *Report {
+	d, err := t.Dist(b.ws)
+	if err != nil {
+		r := &Report{Project: t.Project, Candidate: t.Candidate}
+		r.Record("batch task", false, err)
+		return r
+	}
+
+	l := b.lock(d.dir)
+	l.Lock()
+	defer l.Unlock()
+
+	log.Printf("batch verify %s %s\n", t.Project, d.rc)
+	t.verify(d)
+
+	return d.Report()
+}
+
+// bindBatchFlags bind batch command flags
+func bindBatchFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&batchFile, "file", "f", "", "Specify candidates file, YAML or CSV of project,candidate,commit,announcer")
+	bindReportFlags(flags)
+	flags.UintVarP(&workers, "workers", "w", 4, "Specify number of candidates verified concurrently")
+}
+
+var batchCmd = &cobra.Command{
+	Use:     "batch",
+	Aliases: []string{"all"},
+	Short:   "Verify candidates from file or all open candidates under dist",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var tasks []Task
+		var err error
+		if batchFile != "" {
+			tasks, err = loadTasks(batchFile)
+		} else if commitID != "" {
+			return fmt.Errorf("commit differs per candidate, specify it in candidates file instead")
+		} else {
+			tasks, err = discoverTasks()
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(tasks) == 0 {
+			return fmt.Errorf("no candidate to verify")
+		}
+
+		if err := openWorkspace(); err != nil {
+			return err
+		}
+
+		reports := NewBatch(tasks, int(workers), workspace).Run()
+		return summarize(reports)
+	},
+}
+
+func init() {
+	bindBatchFlags(batchCmd.Flags())
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTasks(t *testing.T) {
+	want := []Task{
+		{Project: "dashboard", Candidate: "2.11.0", Commit: "2c563dc15c54a8deb3ba08707594d4d15da76b1b", Announcer: "Zeping Bai"},
+		{Project: "apisix", Candidate: "3.0.0-beta"},
+	}
+
+	yml := `
+- project: dashboard
+  candidate: 2.11.0
+  commit: 2c563dc15c54a8deb3ba08707594d4d15da76b1b
+  announcer: Zeping Bai
+- project: apisix
+  candidate: 3.0.0-beta
+`
+	got, err := yamlTasks(strings.NewReader(yml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("yamlTasks() = %v, want %v", got, want)
+	}
+
+	csv := `project,candidate,commit,announcer
+dashboard,2.11.0,2c563dc15c54a8deb3ba08707594d4d15da76b1b,Zeping Bai
+# comment line
+apisix,3.0.0-beta
+`
+	got, err = csvTasks(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("csvTasks() = %v, want %v", got, want)
+	}
+}
+
+func TestBatchCmd_discoverWithCommit(t *testing.T) {
+	commitID = "2c563dc15c54a8deb3ba08707594d4d15da76b1b"
+	defer func() { commitID = "" }()
+
+	// commit of one candidate never stamps on discovered ones
+	if err := batchCmd.RunE(batchCmd, nil); err == nil || !strings.Contains(err.Error(), "commit differs per candidate") {
+		t.Errorf("RunE() error = %v, want commit refused", err)
+	}
+}
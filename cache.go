@@ -0,0 +1,441 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// downloadCache cache shared across runs, nil means disabled
+var downloadCache *Cache
+
+// A CacheEntry represents a cached link, content stored by its SHA-512
+type CacheEntry struct {
+	Meta
+	SHA512   string    `json:"sha512"`
+	Accessed time.Time `json:"accessed"`
+}
+
+// A Cache is a content-addressed download cache, index keyed by link
+// validated with ETag and Last-Modified, evicts least recently used entries
+// once exceeds limit
+type Cache struct {
+	dir   string
+	limit int64 // bytes, zero means unlimited
+
+	mu sync.Mutex
+}
+
+// NewCache cache under dir, like $XDG_CACHE_HOME/sixer
+func NewCache(dir string, limit int64) *Cache {
+	return &Cache{dir: dir, limit: limit}
+}
+
+// defaultCacheDir $XDG_CACHE_HOME/sixer or platform user cache directory
+func defaultCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "sixer")
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "sixer")
+	}
+
+	return filepath.Join(os.TempDir(), "sixer-cache")
+}
+
+func (c *Cache) indexPath(link string) string {
+	return filepath.Join(c.dir, "index", fmt.Sprintf("%x.json", sha256.Sum256([]byte(link))))
+}
+
+func (c *Cache) objectPath(sum string) string {
+	return filepath.Join(c.dir, "objects", sum[:2], sum)
+}
+
+func (c *Cache) lookup(link string) (*CacheEntry, error) {
+	body, err := os.ReadFile(c.indexPath(link))
+	if err != nil {
+		return nil, err
+	}
+
+	var e CacheEntry
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func (c *Cache) save(e *CacheEntry) error {
+	body, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.indexPath(e.Link), body)
+}
+
+// Restore copy cached content of link into filename if still up to date with res
+func (c *Cache) Restore(link, filename string, res *http.Response) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookup(link)
+	if err != nil {
+		return false, nil
+	}
+
+	if !e.Meta.match(res) || (res.ContentLength >= 0 && res.ContentLength != e.Size) {
+		return false, nil
+	}
+
+	obj := c.objectPath(e.SHA512)
+	if ok, err := c.intact(obj, e); err != nil || !ok {
+		return false, err
+	}
+
+	if err := linkOrCopy(obj, filename); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := e.Meta.write(filename); err != nil {
+		return false, err
+	}
+
+	e.Accessed = time.Now()
+	return true, c.save(e)
+}
+
+// intact whether object still holds content of entry, evicts entry and
+// object on size or digest mismatch, e.g. modified through a hard link
+func (c *Cache) intact(obj string, e *CacheEntry) (bool, error) {
+	f, err := os.Stat(obj)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if f.Size() == e.Size {
+		sum, err := sha512File(obj)
+		if err != nil {
+			return false, err
+		}
+		if sum == e.SHA512 {
+			return true, nil
+		}
+	}
+
+	if err := os.Remove(c.indexPath(e.Link)); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if err := os.Remove(obj); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// Store add downloaded filename of link into cache, meta written by download
+func (c *Cache) Store(link, filename string) error {
+	meta, err := readMeta(filename)
+	if err != nil {
+		return err
+	}
+	if meta.ETag == "" && meta.LastModified == "" {
+		// never validated later, not worth caching
+		return nil
+	}
+
+	sum, err := sha512File(filename)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	obj := c.objectPath(sum)
+	if _, err := os.Stat(obj); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(obj), 0755); err != nil {
+			return err
+		}
+		if err := linkOrCopy(filename, obj); err != nil {
+			return err
+		}
+	}
+
+	e := &CacheEntry{Meta: *meta, SHA512: sum, Accessed: time.Now()}
+	if err := c.save(e); err != nil {
+		return err
+	}
+
+	return c.evict(c.limit)
+}
+
+// Entries all index entries, least recently used first
+func (c *Cache) Entries() ([]*CacheEntry, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "index", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*CacheEntry
+	for _, f := range files {
+		body, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var e CacheEntry
+		if json.Unmarshal(body, &e) == nil {
+			entries = append(entries, &e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Accessed.Before(entries[j].Accessed)
+	})
+
+	return entries, nil
+}
+
+// evict drop least recently used entries until objects size within limit,
+// then remove dangling entries and unreferenced objects
+func (c *Cache) evict(limit int64) error {
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+
+	sizes := map[string]int64{} // object sum -> size
+	refs := map[string]int{}
+	var total int64
+	var live []*CacheEntry
+	for _, e := range entries {
+		f, err := os.Stat(c.objectPath(e.SHA512))
+		if err != nil {
+			_ = os.Remove(c.indexPath(e.Link))
+			continue
+		}
+		if _, ok := sizes[e.SHA512]; !ok {
+			sizes[e.SHA512] = f.Size()
+			total += f.Size()
+		}
+		refs[e.SHA512]++
+		live = append(live, e)
+	}
+
+	for _, e := range live {
+		if limit <= 0 || total <= limit {
+			break
+		}
+		if err := os.Remove(c.indexPath(e.Link)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if refs[e.SHA512]--; refs[e.SHA512] == 0 {
+			if err := os.Remove(c.objectPath(e.SHA512)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			total -= sizes[e.SHA512]
+		}
+	}
+
+	objects, _ := filepath.Glob(filepath.Join(c.dir, "objects", "*", "*"))
+	for _, obj := range objects {
+		if refs[filepath.Base(obj)] == 0 {
+			_ = os.Remove(obj)
+		}
+	}
+
+	return nil
+}
+
+// Prune evict to limit and remove dangling files
+func (c *Cache) Prune() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.evict(c.limit)
+}
+
+// Clear remove all cached content
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, sub := range []string{"index", "objects"} {
+		if err := os.RemoveAll(filepath.Join(c.dir, sub)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sha512File(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha512.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// writeFileAtomic write into temp file then rename
+func writeFileAtomic(filename string, body []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(filename), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), filename)
+}
+
+// linkOrCopy hard link src to dst, copy if crossing devices
+func linkOrCopy(src, dst string) error {
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+
+	_ = os.Remove(dst)
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + partSuffix
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, dst)
+}
+
+// initCache open download cache from flags
+func initCache() {
+	if noCache {
+		return
+	}
+
+	dir := cacheDir
+	if dir == "" {
+		dir = defaultCacheDir()
+	}
+
+	downloadCache = NewCache(dir, int64(cacheLimit)<<20)
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage download cache shared across runs",
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached links, least recently used first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if downloadCache == nil {
+			return fmt.Errorf("cache disabled")
+		}
+
+		entries, err := downloadCache.Entries()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "SHA512\tSIZE\tACCESSED\tLINK")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", e.SHA512[:16], e.Size, e.Accessed.Format(time.RFC3339), e.Link)
+		}
+
+		return w.Flush()
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Evict least recently used entries beyond size limit",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if downloadCache == nil {
+			return fmt.Errorf("cache disabled")
+		}
+
+		return downloadCache.Prune()
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached content",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if downloadCache == nil {
+			return fmt.Errorf("cache disabled")
+		}
+
+		return downloadCache.Clear()
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd, cachePruneCmd, cacheClearCmd)
+}
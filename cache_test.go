@@ -0,0 +1,134 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	contents := map[string][]byte{
+		"/a.tgz": bytes.Repeat([]byte("a"), 600),
+		"/b.tgz": bytes.Repeat([]byte("b"), 600),
+	}
+
+	gets := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+		}
+		w.Header().Set("ETag", `"`+r.URL.Path+`"`)
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(contents[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	c := NewCache(t.TempDir(), 1000)
+	l := &Linker{timeout: 3, cache: c}
+
+	first := filepath.Join(t.TempDir(), "a.tgz")
+	if err := l.Download(srv.URL+"/a.tgz", first); err != nil {
+		t.Fatal(err)
+	}
+
+	// another run restores from cache without download
+	second := filepath.Join(t.TempDir(), "a.tgz")
+	if err := l.Download(srv.URL+"/a.tgz", second); err != nil {
+		t.Fatal(err)
+	}
+	if gets != 1 {
+		t.Errorf("cached link downloaded again, gets %d", gets)
+	}
+	if got, _ := os.ReadFile(second); !bytes.Equal(got, contents["/a.tgz"]) {
+		t.Error("restored content mismatch")
+	}
+
+	// least recently used entry evicted beyond limit
+	if err := l.Download(srv.URL+"/b.tgz", filepath.Join(t.TempDir(), "b.tgz")); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Link != srv.URL+"/b.tgz" {
+		t.Errorf("entries after eviction = %v", entries)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := c.Entries(); len(entries) != 0 {
+		t.Errorf("entries after clear = %d", len(entries))
+	}
+}
+
+func TestCache_RestoreCorrupted(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 600)
+	gets := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+		}
+		w.Header().Set("ETag", `"a"`)
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	c := NewCache(t.TempDir(), 0)
+	l := &Linker{timeout: 3, cache: c}
+
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{name: "truncated", body: []byte("a")},
+		{name: "same size", body: bytes.Repeat([]byte("x"), 600)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := filepath.Join(t.TempDir(), "a.tgz")
+			if err := l.Download(srv.URL+"/a.tgz", first); err != nil {
+				t.Fatal(err)
+			}
+
+			// modified in place, object shares the same inode
+			entries, err := c.Entries()
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("entries = %v, error = %v", entries, err)
+			}
+			if err := os.WriteFile(c.objectPath(entries[0].SHA512), tt.body, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			gets = 0
+			second := filepath.Join(t.TempDir(), "a.tgz")
+			if err := l.Download(srv.URL+"/a.tgz", second); err != nil {
+				t.Fatal(err)
+			}
+			if gets != 1 {
+				t.Errorf("corrupted object restored, gets %d", gets)
+			}
+			if got, _ := os.ReadFile(second); !bytes.Equal(got, content) {
+				t.Error("downloaded content mismatch")
+			}
+		})
+	}
+}
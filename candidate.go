@@ -14,67 +14,240 @@
 //
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 const (
-	baseLink     = "https://dist.apache.org/repos/dist/dev/apisix/"
-	prefixApache = "apache"
+	distDevLink   = "https://dist.apache.org/repos/dist/dev/"
+	projectAPISix = "apisix"
+	baseLink      = distDevLink + projectAPISix + "/"
+	prefixApache  = "apache"
+
+	// distReleaseLink release area which voted releases move into
+	distReleaseLink = "https://dist.apache.org/repos/dist/release/"
+	// archiveDistLink archive of all releases ever made
+	archiveDistLink = "https://archive.apache.org/dist/"
+)
+
+// Naming rules derive release names from candidate version, placeholders:
+// {pkg} {prefix} {version} {candidate} {major} {minor} {patch} {pre} {rc}
+type Naming struct {
+	Dir    string // dist directory, like {pkg}-{version}
+	Src    string // source package name prefix, like {prefix}-{pkg}-{version}
+	Tag    string // git tag, like v{version}
+	Branch string // release branch, like release/{major}.{minor}
+	Anchor string // CHANGELOG heading, like {version}
+	// Archive suffix of source package after Src, like -src.tgz -src.zip .tar.gz,
+	// format follows the extension
+	Archive string
+	// Top single top-level directory of source package, like {prefix}-{pkg}-{version}-src,
+	// "." means files lie at archive root
+	Top string
+}
+
+var (
+	// defaultNaming naming rules of sub-projects
+	defaultNaming = Naming{
+		Dir:     "{pkg}-{version}",
+		Src:     "{prefix}-{pkg}-{version}",
+		Tag:     "{version}",
+		Branch:  "release/{major}.{minor}",
+		Anchor:  "{version}",
+		Archive: "-src.tgz",
+		Top:     ".",
+	}
+)
+
+var (
+	namingPlaceholder = regexp.MustCompile(`\{[a-z]+\}`)
+	namingPatterns    = map[string]string{
+		"{version}":   `(?P<version>v?\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)`,
+		"{candidate}": `(?P<candidate>v?\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)`,
+		"{major}":     `(?P<major>\d+)`,
+		"{minor}":     `(?P<minor>\d+)`,
+		"{patch}":     `(?P<patch>\d+)`,
+		"{pre}":       `(?P<pre>[0-9A-Za-z.-]*)`,
+		"{rc}":        `(?P<rc>rc\d+)`,
+	}
 )
 
 // A Candidate represents package with specified version
 type Candidate struct {
-	pkg       string // package name, like: apisix-dashboard
-	rc        string // release candidate version, like: 0.2.0
-	sub       bool   // sub-project
-	pkgPrefix string // package name prefix, like:apache
+	pkg        string // package name, like: apisix-dashboard
+	rc         Semver // release candidate version, like: 0.2.0
+	pkgPrefix  string // package name prefix, like:apache
+	naming     Naming // release naming rules
+	project    string // ASF project or podling which releases package, defaults to apisix
+	incubating bool   // podling of Incubator, released as -incubating
+}
+
+func (c *Candidate) expand(tmpl string) string {
+	r := strings.NewReplacer(
+		"{pkg}", c.pkg,
+		"{prefix}", c.pkgPrefix,
+		"{version}", c.rc.Version(),
+		"{candidate}", c.rc.String(),
+		"{major}", strconv.FormatUint(c.rc.Major, 10),
+		"{minor}", strconv.FormatUint(c.rc.Minor, 10),
+		"{patch}", strconv.FormatUint(c.rc.Patch, 10),
+		"{pre}", c.rc.Pre,
+		"{rc}", c.rc.RCName(),
+	)
+
+	return r.Replace(tmpl)
+}
+
+// MatchPackage reverse dist directory naming rule, returns version of directory
+func (c *Candidate) MatchPackage(name string) (Semver, bool) {
+	return c.match(c.naming.Dir, name)
+}
+
+// MatchSrc reverse source package naming rule, returns version of package name prefix
+func (c *Candidate) MatchSrc(prefix string) (Semver, bool) {
+	return c.match(c.naming.Src, prefix)
+}
+
+func (c *Candidate) match(tmpl, name string) (Semver, bool) {
+	var pattern strings.Builder
+	pos := 0
+	for _, loc := range namingPlaceholder.FindAllStringIndex(tmpl, -1) {
+		pattern.WriteString(regexp.QuoteMeta(tmpl[pos:loc[0]]))
+		switch p := tmpl[loc[0]:loc[1]]; p {
+		case "{pkg}":
+			pattern.WriteString(regexp.QuoteMeta(c.pkg))
+		case "{prefix}":
+			pattern.WriteString(regexp.QuoteMeta(c.pkgPrefix))
+		default:
+			pattern.WriteString(namingPatterns[p])
+		}
+		pos = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(tmpl[pos:]))
+
+	re, err := regexp.Compile("^" + pattern.String() + "$")
+	if err != nil {
+		return Semver{}, false
+	}
+
+	m := re.FindStringSubmatch(name)
+	if m == nil {
+		return Semver{}, false
+	}
+
+	groups := map[string]string{}
+	for i, g := range re.SubexpNames() {
+		if g != "" && m[i] != "" {
+			groups[g] = m[i]
+		}
+	}
+
+	v, ok := groups["candidate"]
+	if !ok {
+		v, ok = groups["version"]
+	}
+	if !ok {
+		v = fmt.Sprintf("%s.%s.%s", groups["major"], groups["minor"], groups["patch"])
+		if pre := groups["pre"]; pre != "" {
+			v = fmt.Sprintf("%s-%s", v, pre)
+		}
+		if rc := groups["rc"]; rc != "" {
+			v = fmt.Sprintf("%s-%s", v, rc)
+		}
+	}
+
+	ver, err := ParseSemver(v)
+	return ver, err == nil
+}
+
+// DistLink URL of dist directory which holds all candidates
+func (c *Candidate) DistLink() string {
+	return c.AreaLink(distDevLink)
+}
+
+// AreaLink URL of project directory under dist area base, like dev, release or archive
+func (c *Candidate) AreaLink(base string) string {
+	project := c.project
+	if project == "" {
+		project = projectAPISix
+	}
+
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	if c.incubating {
+		return fmt.Sprintf("%sincubator/%s/", base, project)
+	}
+	return fmt.Sprintf("%s%s/", base, project)
+}
+
+// KeysLink URL of project KEYS file
+func (c *Candidate) KeysLink() string {
+	return c.DistLink() + "KEYS"
 }
 
 // PackageLink complete URL for package directory
 func (c *Candidate) PackageLink() string {
-	return fmt.Sprintf("%s%s", baseLink, c.Package())
+	return fmt.Sprintf("%s%s", c.DistLink(), c.Package())
 }
 
-// Package a package name with prefix "apisix"
+// Package dist directory name, like apisix-dashboard-2.11.0
 func (c *Candidate) Package() string {
-	if c.sub {
-		return fmt.Sprintf("%s-%s", c.pkg, c.rc)
-	}
-
-	return c.rc
+	return c.expand(c.naming.Dir)
 }
 
 // SrcPrefix src file name with package prefix
 func (c *Candidate) SrcPrefix() string {
-	if c.pkgPrefix != "" {
-		return fmt.Sprintf("%s-%s-%s", c.pkgPrefix, c.pkg, c.rc)
-	}
+	return c.expand(c.naming.Src)
+}
+
+// Tag git tag of release
+func (c *Candidate) Tag() string {
+	return c.expand(c.naming.Tag)
+}
+
+// Branch git release branch
+func (c *Candidate) Branch() string {
+	return c.expand(c.naming.Branch)
+}
+
+// Anchor CHANGELOG heading of release
+func (c *Candidate) Anchor() string {
+	return c.expand(c.naming.Anchor)
+}
 
-	return c.Package()
+// srcArchive source package file name, prefix with archive suffix
+func (c *Candidate) srcArchive() string {
+	return c.SrcPrefix() + c.naming.Archive
 }
 
-func (c *Candidate) srcTgz() string {
-	return fmt.Sprintf("%s-src.tgz", c.SrcPrefix())
+// TopDir top-level directory of source package, "." for archive root
+func (c *Candidate) TopDir() string {
+	return c.expand(c.naming.Top)
 }
 
 // SrcLink source package URL
 func (c *Candidate) SrcLink() string {
-	return fmt.Sprintf("%s/%s", c.PackageLink(), c.srcTgz())
+	return fmt.Sprintf("%s/%s", c.PackageLink(), c.srcArchive())
 }
 
-func (c *Candidate) srcTgzAsc() string {
-	return fmt.Sprintf("%s-src.tgz.asc", c.SrcPrefix())
+func (c *Candidate) srcAsc() string {
+	return c.srcArchive() + ".asc"
 }
 
 // SrcAscLink source package asc URL
 func (c *Candidate) SrcAscLink() string {
-	return fmt.Sprintf("%s/%s", c.PackageLink(), c.srcTgzAsc())
+	return fmt.Sprintf("%s/%s", c.PackageLink(), c.srcAsc())
 }
 
-func (c *Candidate) srcTgzSha512() string {
-	return fmt.Sprintf("%s-src.tgz.sha512", c.SrcPrefix())
+func (c *Candidate) srcSha512() string {
+	return c.srcArchive() + ".sha512"
 }
 
 // SrcSha512Link source package sha512 URL
 func (c *Candidate) SrcSha512Link() string {
-	return fmt.Sprintf("%s/%s", c.PackageLink(), c.srcTgzSha512())
+	return fmt.Sprintf("%s/%s", c.PackageLink(), c.srcSha512())
 }
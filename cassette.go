@@ -0,0 +1,185 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// errNotRecorded request not found in cassette when replaying
+var errNotRecorded = errors.New("interaction not recorded")
+
+// An Interaction represents one recorded request and its response,
+// response body saves aside as file
+type Interaction struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Range  string      `json:"range,omitempty"`
+	Status string      `json:"status"`
+	Code   int         `json:"code"`
+	Header http.Header `json:"header"`
+}
+
+// cassetteKey file name of request in cassette directory
+func cassetteKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s %s %s", req.Method, req.URL, req.Header.Get("Range"))))
+	return fmt.Sprintf("%x", sum[:16])
+}
+
+// A Recorder saves every interaction into cassette directory
+type Recorder struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// NewRecorder record interactions passing through next
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Recorder{dir: dir, next: next}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	key := filepath.Join(r.dir, cassetteKey(req))
+	if err := r.save(key+".body", func(w io.Writer) error {
+		_, err := io.Copy(w, res.Body)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	it := Interaction{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Range:  req.Header.Get("Range"),
+		Status: res.Status,
+		Code:   res.StatusCode,
+		Header: res.Header,
+	}
+	meta, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.save(key+".json", func(w io.Writer) error {
+		_, err := w.Write(meta)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	body, err := os.Open(key + ".body")
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = body
+	return res, nil
+}
+
+// save write file through a temporary one renamed into place, concurrent
+// identical requests never see it truncated
+func (r *Recorder) save(filename string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(r.dir, filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// A Replayer serves recorded interactions without network
+type Replayer struct {
+	dir string
+}
+
+// NewReplayer replay interactions from cassette directory
+func NewReplayer(dir string) (*Replayer, error) {
+	if f, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !f.IsDir() {
+		return nil, fmt.Errorf("cassette %s not a directory", dir)
+	}
+
+	return &Replayer{dir: dir}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := filepath.Join(r.dir, cassetteKey(req))
+	meta, err := os.ReadFile(key + ".json")
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s %s", errNotRecorded, req.Method, req.URL)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var it Interaction
+	if err := json.Unmarshal(meta, &it); err != nil {
+		return nil, err
+	}
+
+	body, err := os.Open(key + ".body")
+	if err != nil {
+		return nil, err
+	}
+
+	res := &http.Response{
+		Status:     it.Status,
+		StatusCode: it.Code,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     it.Header,
+		Body:       body,
+		Request:    req,
+	}
+	res.ContentLength = -1
+	if req.Method == http.MethodHead {
+		if n, err := strconv.ParseInt(it.Header.Get("Content-Length"), 10, 64); err == nil {
+			res.ContentLength = n
+		}
+	} else if f, err := body.Stat(); err == nil {
+		res.ContentLength = f.Size()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCassette(t *testing.T) {
+	content := bytes.Repeat([]byte("dashboard"), 512)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(distIndex))
+		case "/src.tgz":
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "src.tgz", time.Time{}, bytes.NewReader(content))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cassette := t.TempDir()
+	verify := func(l *Linker, dir string) {
+		if body, err := l.Get(srv.URL + "/"); err != nil || body != distIndex {
+			t.Fatalf("Get() = %s, %v", body, err)
+		}
+		if ok, err := l.Head(srv.URL + "/src.tgz"); !ok {
+			t.Fatalf("Head() error = %v", err)
+		}
+		if _, err := l.Head(srv.URL + "/missing"); errorKind(err) != kindMissing {
+			t.Fatalf("Head() missing error = %v", err)
+		}
+
+		filename := filepath.Join(dir, "src.tgz")
+		if err := l.Download(srv.URL+"/src.tgz", filename); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(filename); !bytes.Equal(got, content) {
+			t.Fatal("downloaded content mismatch")
+		}
+	}
+
+	recorder, err := NewRecorder(cassette, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify(&Linker{transport: recorder}, t.TempDir())
+
+	srv.Close()
+	replayer, err := NewReplayer(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &Linker{transport: replayer, retrier: Retrier{Retries: 3, Backoff: time.Hour}}
+	verify(l, t.TempDir())
+
+	if _, err := l.Head(srv.URL + "/not-recorded"); !errors.Is(err, errNotRecorded) {
+		t.Errorf("Head() not recorded error = %v", err)
+	}
+}
+
+func TestRecorder_Concurrent(t *testing.T) {
+	content := bytes.Repeat([]byte("dashboard"), 64*1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	recorder, err := NewRecorder(t.TempDir(), http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &Linker{transport: recorder}
+
+	// identical requests record the same files while others read them
+	errs := make([]error, 8)
+	parallel(8, len(errs), func(i int) {
+		body, err := l.Get(srv.URL + "/src.tgz")
+		if err == nil && body != string(content) {
+			err = errors.New("body truncated")
+		}
+		errs[i] = err
+	})
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("request %d: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+const checkExtras = "extras"
+
+// defaultChecks archive checks of project unless its definition says otherwise
+var defaultChecks = []string{checkLayout, checkSafety}
+
+// An ArchiveCheck inspects archive entries during the one decompression pass,
+// a fresh instance is created for each archive
+type ArchiveCheck interface {
+	// Begin called before the first entry with name of archive
+	Begin(archive string) error
+	// Entry called with header and content of each entry in order
+	Entry(hdr *tar.Header, r io.Reader) error
+	// End called after the last entry, results go into report
+	End() []Result
+}
+
+// A CheckFactory creates check for candidate of dist
+type CheckFactory func(d *Dist) ArchiveCheck
+
+var (
+	checksMu sync.RWMutex
+	checks   = map[string]CheckFactory{}
+)
+
+// RegisterArchiveCheck make check available by name to project definitions,
+// it panics if registered twice
+func RegisterArchiveCheck(name string, factory CheckFactory) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+
+	if factory == nil {
+		panic("sixer: register archive check " + name + " with nil factory")
+	}
+	if _, ok := checks[name]; ok {
+		panic("sixer: register archive check " + name + " twice")
+	}
+	checks[name] = factory
+}
+
+// ArchiveChecks names of registered checks, sorted
+func ArchiveChecks() []string {
+	checksMu.RLock()
+	defer checksMu.RUnlock()
+
+	var names []string
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// newArchiveCheck create check of name for dist
+func newArchiveCheck(name string, d *Dist) (ArchiveCheck, error) {
+	checksMu.RLock()
+	factory, ok := checks[name]
+	checksMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("archive check %s not registered", name)
+	}
+
+	return factory(d), nil
+}
+
+// runningCheck a check in one pass, later entries skip once it failed
+type runningCheck struct {
+	name  string
+	check ArchiveCheck
+	err   error
+}
+
+func (c *runningCheck) visit(hdr *tar.Header, r io.Reader) error {
+	if c.err == nil {
+		c.err = c.check.Entry(hdr, r)
+	}
+
+	// one check's failure never stops others
+	return nil
+}
+
+// results of the check, failure of it comes first
+func (c *runningCheck) results() []Result {
+	var results []Result
+	if c.err != nil {
+		results = append(results, Result{Item: fmt.Sprintf("%s check", c.name), Detail: c.err.Error()})
+	}
+
+	return append(results, c.check.End()...)
+}
+
+// extrasCheck look for LICENSE and NOTICE at top level
+type extrasCheck struct {
+	license bool
+	notice  bool
+}
+
+func (c *extrasCheck) Begin(archive string) error {
+	return nil
+}
+
+func (c *extrasCheck) Entry(hdr *tar.Header, r io.Reader) error {
+	if hdr.Typeflag != tar.TypeReg {
+		return nil
+	}
+
+	switch hdr.Name {
+	case "LICENSE", "./LICENSE":
+		c.license = true
+	case "NOTICE", "./NOTICE":
+		c.notice = true
+	}
+
+	return nil
+}
+
+func (c *extrasCheck) End() []Result {
+	return []Result{
+		{Item: "LICENSE", OK: c.license},
+		{Item: "NOTICE", OK: c.notice},
+	}
+}
+
+func init() {
+	RegisterArchiveCheck(checkExtras, func(d *Dist) ArchiveCheck {
+		return &extrasCheck{}
+	})
+}
+
+// contains whether names has name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
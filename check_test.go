@@ -0,0 +1,105 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// versionCheck finds version string in README
+type versionCheck struct {
+	version string
+	found   bool
+}
+
+func (c *versionCheck) Begin(archive string) error { return nil }
+
+func (c *versionCheck) Entry(hdr *tar.Header, r io.Reader) error {
+	if hdr.Name != "README.md" {
+		return nil
+	}
+
+	body, err := io.ReadAll(r)
+	c.found = strings.Contains(string(body), c.version)
+	return err
+}
+
+func (c *versionCheck) End() []Result {
+	return []Result{{Item: "README version", OK: c.found}}
+}
+
+// brokenCheck fails on the first entry
+type brokenCheck struct{}
+
+func (c *brokenCheck) Begin(archive string) error { return nil }
+
+func (c *brokenCheck) Entry(hdr *tar.Header, r io.Reader) error {
+	return fmt.Errorf("broken on %s", hdr.Name)
+}
+
+func (c *brokenCheck) End() []Result { return nil }
+
+func init() {
+	RegisterArchiveCheck("test-version", func(d *Dist) ArchiveCheck {
+		return &versionCheck{version: d.rc.Version()}
+	})
+	RegisterArchiveCheck("test-broken", func(d *Dist) ArchiveCheck {
+		return &brokenCheck{}
+	})
+}
+
+func TestArchiveCheck(t *testing.T) {
+	dir := t.TempDir()
+	name := "apache-apisix-dashboard-2.11.0-src.tgz"
+	pkg := tgz(t, []string{"LICENSE", "NOTICE", "README.md"},
+		map[string]string{"LICENSE": "Apache License", "NOTICE": "Apache APISIX", "README.md": "Dashboard 2.11.0"})
+	keys := writeRelease(t, dir, name, pkg, newSigner(t, "Zeping Bai"))
+
+	d := localDist(filepath.Join(dir, name))
+	d.keys = keys
+	d.checks = []string{"test-broken", checkExtras, "test-version"}
+	d.Verify()
+
+	got := map[string]Result{}
+	for _, res := range d.Report().Results {
+		got[res.Item] = res
+	}
+
+	for _, item := range []string{"LICENSE", "NOTICE", "README version"} {
+		if !got[item].OK {
+			t.Errorf("%s = %+v, want ok", item, got[item])
+		}
+	}
+	if res := got["test-broken check"]; res.OK || res.Detail != "broken on LICENSE" {
+		t.Errorf("test-broken check = %+v, want bad", res)
+	}
+
+	d.checks = []string{"unknown"}
+	if _, err := d.CheckExtras(); err == nil {
+		t.Error("unregistered check expect error")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("register twice expect panic")
+		}
+	}()
+	RegisterArchiveCheck(checkExtras, func(d *Dist) ArchiveCheck { return &extrasCheck{} })
+}
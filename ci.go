@@ -0,0 +1,165 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+)
+
+// requiredCI CI checks from flag which must have succeeded, besides project's
+var requiredCI []string
+
+// A CIRun is a commit status or a check run of the commit
+type CIRun struct {
+	Name       string
+	Status     string // queued, in_progress or completed
+	Conclusion string // success, failure, neutral, skipped and so on, empty until completed
+}
+
+// Succeeded whether run completed successfully, neutral or skipped one not
+func (r CIRun) Succeeded() bool {
+	return r.Status == "completed" && r.Conclusion == "success"
+}
+
+// String conclusion of completed run, otherwise its status
+func (r CIRun) String() string {
+	if r.Status != "completed" {
+		return r.Status
+	}
+
+	return r.Conclusion
+}
+
+// Statuses commit statuses of ref, latest of each context
+func (a *GitHubAPI) Statuses(ref string) ([]CIRun, error) {
+	var combined struct {
+		Statuses []struct {
+			Context string `json:"context"`
+			State   string `json:"state"`
+		} `json:"statuses"`
+	}
+	if err := a.get(fmt.Sprintf("/commits/%s/status?per_page=100", escapeRef(ref)), &combined); err != nil {
+		return nil, err
+	}
+
+	var runs []CIRun
+	for _, s := range combined.Statuses {
+		run := CIRun{Name: s.Context, Status: "completed", Conclusion: s.State}
+		switch s.State {
+		case "pending":
+			run = CIRun{Name: s.Context, Status: "in_progress"}
+		case "error":
+			run.Conclusion = "failure"
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// CheckRuns latest check runs of ref, like GitHub Actions jobs, all pages
+func (a *GitHubAPI) CheckRuns(ref string) ([]CIRun, error) {
+	var runs []CIRun
+	for page := 1; ; page++ {
+		var list struct {
+			TotalCount int `json:"total_count"`
+			CheckRuns  []struct {
+				Name       string `json:"name"`
+				Status     string `json:"status"`
+				Conclusion string `json:"conclusion"`
+			} `json:"check_runs"`
+		}
+		query := url.Values{"filter": {"latest"}, "per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		if err := a.get(fmt.Sprintf("/commits/%s/check-runs?%s", escapeRef(ref), query.Encode()), &list); err != nil {
+			return nil, err
+		}
+
+		for _, r := range list.CheckRuns {
+			runs = append(runs, CIRun{Name: r.Name, Status: r.Status, Conclusion: r.Conclusion})
+		}
+		if len(list.CheckRuns) == 0 || len(runs) >= list.TotalCount {
+			return runs, nil
+		}
+	}
+}
+
+// CIRuns commit statuses and check runs of ref, sorted by name
+func (a *GitHubAPI) CIRuns(ref string) ([]CIRun, error) {
+	statuses, err := a.Statuses(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	checks, err := a.CheckRuns(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := append(statuses, checks...)
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Name < runs[j].Name })
+	return runs, nil
+}
+
+// ValidCI report conclusion of each CI run of commit, required ones must
+// have succeeded; a check of several runs, like of several workflows, passes
+// only if every latest run succeeded. Returns error only if API unreachable,
+// like ValidAPI
+func (g *GitHub) ValidCI(api *GitHubAPI, required []string) error {
+	commit := g.git.Commit
+	if commit == "" {
+		return nil
+	}
+
+	runs, err := api.CIRuns(commit)
+	if err != nil {
+		g.report.Record(fmt.Sprintf("github ci of commit %s", commit), false, err)
+		if isUnreachable(err) {
+			return err
+		}
+		return nil
+	}
+
+	found := make(map[string]bool)
+	failed := make(map[string]string)
+	for _, run := range runs {
+		if run.Succeeded() {
+			log.Printf("github ci %s ok ✅\n", run.Name)
+		} else {
+			log.Printf("github ci %s bad ❌ %s\n", run.Name, run)
+			if _, ok := failed[run.Name]; !ok {
+				failed[run.Name] = run.String()
+			}
+		}
+		found[run.Name] = true
+	}
+
+	for _, name := range required {
+		item := fmt.Sprintf("github ci required %s succeeded", name)
+		state, bad := failed[name]
+		switch {
+		case bad:
+			g.report.Record(item, false, fmt.Errorf("%s on commit %s", state, commit))
+		case found[name]:
+			g.report.Record(item, true, nil)
+		default:
+			g.report.Record(item, false, fmt.Errorf("not found on commit %s", commit))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ciAPI statuses and check runs of release commit, check runs over two pages
+func ciAPI() map[string]interface{} {
+	commits := "/repos/apache/apisix-dashboard/commits/3f2e1d0"
+	return map[string]interface{}{
+		commits + "/status?per_page=100": map[string]interface{}{
+			"state": "pending",
+			"statuses": []map[string]string{
+				{"context": "license/cla", "state": "success"},
+				{"context": "codecov/project", "state": "pending"},
+			},
+		},
+		commits + "/check-runs?filter=latest&page=1&per_page=100": map[string]interface{}{
+			"total_count": 7,
+			"check_runs": []map[string]interface{}{
+				{"name": "backend-unit-test", "status": "completed", "conclusion": "success"},
+				{"name": "frontend-e2e-test", "status": "completed", "conclusion": "failure"},
+				{"name": "lint", "status": "completed", "conclusion": "failure"},
+				{"name": "docs", "status": "completed", "conclusion": "skipped"},
+				{"name": "markdown", "status": "completed", "conclusion": "neutral"},
+			},
+		},
+		commits + "/check-runs?filter=latest&page=2&per_page=100": map[string]interface{}{
+			"total_count": 7,
+			"check_runs": []map[string]interface{}{
+				{"name": "lint", "status": "completed", "conclusion": "success"},
+				{"name": "backend-unit-test", "status": "completed", "conclusion": "success"},
+			},
+		},
+	}
+}
+
+func TestGitHub_ValidCI(t *testing.T) {
+	srv := fakeGitHub(t, "", ciAPI())
+	defer srv.Close()
+
+	git := &Git{Repo: pkgAPISixDashboard, Commit: "3f2e1d0"}
+	api := NewGitHubAPI(srv.URL, git.Repo)
+	api.Linker = Linker{timeout: 3, workers: 4}
+
+	required := []string{"backend-unit-test", "lint", "license/cla", "frontend-e2e-test", "codecov/project", "docs", "markdown", "build"}
+	want := []Result{
+		{Item: "github ci required backend-unit-test succeeded", OK: true},               // every workflow succeeded
+		{Item: "github ci required lint succeeded", Detail: "failure on commit 3f2e1d0"}, // one of workflows failed
+		{Item: "github ci required license/cla succeeded", OK: true},
+		{Item: "github ci required frontend-e2e-test succeeded", Detail: "failure on commit 3f2e1d0"},
+		{Item: "github ci required codecov/project succeeded", Detail: "in_progress on commit 3f2e1d0"},
+		{Item: "github ci required docs succeeded", Detail: "skipped on commit 3f2e1d0"},
+		{Item: "github ci required markdown succeeded", Detail: "neutral on commit 3f2e1d0"},
+		{Item: "github ci required build succeeded", Detail: "not found on commit 3f2e1d0"},
+	}
+
+	g := &GitHub{git: git, report: &Report{}}
+	if err := g.ValidCI(api, required); err != nil {
+		t.Fatalf("ValidCI() error = %v", err)
+	}
+	if len(g.report.Results) != len(want) {
+		t.Fatalf("results = %+v, want %d", g.report.Results, len(want))
+	}
+	for i, res := range g.report.Results {
+		if res.Item != want[i].Item || res.OK != want[i].OK || res.Detail != want[i].Detail {
+			t.Errorf("result %d = %+v, want %+v", i, res, want[i])
+		}
+	}
+
+	// nothing required, conclusions only logged
+	g = &GitHub{git: git, report: &Report{}}
+	if err := g.ValidCI(api, nil); err != nil || len(g.report.Results) != 0 {
+		t.Errorf("ValidCI() = %v, results %+v", err, g.report.Results)
+	}
+}
+
+func TestWithRequiredCI(t *testing.T) {
+	requiredCI = []string{"lint", licenseCheck}
+	defer func() { requiredCI = nil }()
+
+	want := []string{licenseCheck, "lint"}
+	if got := withRequiredCI(licenseCheck); !reflect.DeepEqual(got, want) {
+		t.Errorf("withRequiredCI() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// rockspecDependency quoted dependency in rockspec, like "lua-resty-etcd = 1.6.0"
+var rockspecDependency = regexp.MustCompile(`"([A-Za-z0-9_.-]+)\s*([^"]*)"`)
+
+// A DependencyChange is a dependency added, removed or whose version changed,
+// From empty if added and To empty if removed
+type DependencyChange struct {
+	File string `json:"file"`
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// String like go.mod github.com/spf13/cobra v1.3.0 -> v1.4.0
+func (c DependencyChange) String() string {
+	switch {
+	case c.From == "":
+		return c.File + " + " + c.Name + " " + c.To
+	case c.To == "":
+		return c.File + " - " + c.Name + " " + c.From
+	default:
+		return c.File + " " + c.Name + " " + c.From + " -> " + c.To
+	}
+}
+
+// isManifest whether file declares dependencies
+func isManifest(name string) bool {
+	base := path.Base(name)
+	return base == "go.mod" || base == "package.json" || strings.HasSuffix(base, ".rockspec")
+}
+
+// dependencies name to version of manifest file, nil if not a manifest
+func dependencies(name string, body []byte) map[string]string {
+	base := path.Base(name)
+	switch {
+	case base == "go.mod":
+		return goModDependencies(body)
+	case base == "package.json":
+		return packageDependencies(body)
+	case strings.HasSuffix(base, ".rockspec"):
+		return rockspecDependencies(body)
+	default:
+		return nil
+	}
+}
+
+// goModDependencies required modules of go.mod, in single line or block
+func goModDependencies(body []byte) map[string]string {
+	deps := map[string]string{}
+	block := false
+	for _, line := range strings.Split(string(body), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case block && fields[0] == ")":
+			block = false
+		case block && len(fields) >= 2:
+			deps[fields[0]] = fields[1]
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			block = true
+		case fields[0] == "require" && len(fields) >= 3:
+			deps[fields[1]] = fields[2]
+		}
+	}
+
+	return deps
+}
+
+// packageDependencies dependencies of package.json, dev and peer ones included
+func packageDependencies(body []byte) map[string]string {
+	var pkg struct {
+		Dependencies     map[string]string `json:"dependencies"`
+		DevDependencies  map[string]string `json:"devDependencies"`
+		PeerDependencies map[string]string `json:"peerDependencies"`
+	}
+	if err := json.Unmarshal(body, &pkg); err != nil {
+		return map[string]string{}
+	}
+
+	deps := map[string]string{}
+	for _, m := range []map[string]string{pkg.PeerDependencies, pkg.DevDependencies, pkg.Dependencies} {
+		for name, version := range m {
+			deps[name] = version
+		}
+	}
+
+	return deps
+}
+
+// rockspecDependencies entries of dependencies table in rockspec
+func rockspecDependencies(body []byte) map[string]string {
+	deps := map[string]string{}
+	text := string(body)
+	i := strings.Index(text, "dependencies")
+	if i < 0 {
+		return deps
+	}
+	text = text[i:]
+	open, end := strings.Index(text, "{"), strings.Index(text, "}")
+	if open < 0 || end < open {
+		return deps
+	}
+
+	for _, m := range rockspecDependency.FindAllStringSubmatch(text[open:end], -1) {
+		deps[m[1]] = strings.TrimSpace(m[2])
+	}
+
+	return deps
+}
+
+// diffDependencies changes of dependencies between manifests of two trees,
+// manifests added or removed count as all their dependencies
+func diffDependencies(from, to *fileTree) []DependencyChange {
+	manifests := map[string]bool{}
+	for _, t := range []*fileTree{from, to} {
+		for name := range t.files {
+			if isManifest(name) {
+				manifests[name] = true
+			}
+		}
+	}
+
+	var changes []DependencyChange
+	for name := range manifests {
+		before, after := treeDependencies(from, name), treeDependencies(to, name)
+		for dep, v := range after {
+			if old, ok := before[dep]; !ok || old != v {
+				changes = append(changes, DependencyChange{File: name, Name: dep, From: old, To: v})
+			}
+		}
+		for dep, v := range before {
+			if _, ok := after[dep]; !ok {
+				changes = append(changes, DependencyChange{File: name, Name: dep, From: v})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].File != changes[j].File {
+			return changes[i].File < changes[j].File
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// treeDependencies dependencies of manifest name in tree, empty if absent
+func treeDependencies(t *fileTree, name string) map[string]string {
+	f, ok := t.files[name]
+	if !ok {
+		return nil
+	}
+
+	return dependencies(name, f.body)
+}
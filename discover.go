@@ -0,0 +1,163 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	hrefDir  = regexp.MustCompile(`href="([^".?/][^"]*)/"`)
+	hrefFile = regexp.MustCompile(`href="([^".?/][^"/]*)"`)
+)
+
+// listing extract sub-directory names from dist directory index page
+func listing(body string) []string {
+	var dirs []string
+	for _, m := range hrefDir.FindAllStringSubmatch(body, -1) {
+		dirs = append(dirs, strings.TrimSuffix(m[1], "/"))
+	}
+
+	return dirs
+}
+
+// fileListing extract file names from dist directory index page
+func fileListing(body string) []string {
+	var files []string
+	for _, m := range hrefFile.FindAllStringSubmatch(body, -1) {
+		files = append(files, m[1])
+	}
+
+	return files
+}
+
+// Candidates list candidates under dist directory, sorted ascending
+func (d *Dist) Candidates() ([]Semver, error) {
+	body, err := d.Linker.Get(d.DistLink())
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Semver
+	for _, dir := range listing(body) {
+		if v, ok := d.MatchPackage(dir); ok {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) < 0
+	})
+
+	return versions, nil
+}
+
+// Latest the newest candidate under dist directory
+func (d *Dist) Latest() (Semver, error) {
+	versions, err := d.Candidates()
+	if err != nil {
+		return Semver{}, err
+	}
+
+	if len(versions) == 0 {
+		return Semver{}, fmt.Errorf("not found any %s candidate under %s", d.pkg, d.DistLink())
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+// projectName name of project command which cmd belongs to
+func projectName(cmd *cobra.Command) string {
+	for c := cmd; c != nil && c.HasParent(); c = c.Parent() {
+		if c.Parent() == sixer {
+			return c.Name()
+		}
+	}
+
+	return ""
+}
+
+// resolveLatest fill candidate with the latest one if required
+func resolveLatest(cmd *cobra.Command) error {
+	if !latest || candidate != "" {
+		return nil
+	}
+
+	dist := dist(projectName(cmd))
+	if dist == nil {
+		return fmt.Errorf("latest candidate unsupported")
+	}
+
+	rc, err := dist.Latest()
+	if err != nil {
+		return err
+	}
+
+	candidate = rc.String()
+	log.Printf("dist latest candidate %s\n", candidate)
+	return nil
+}
+
+// completeCandidate offer available candidates of project for -c
+func completeCandidate(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dist := dist(projectName(cmd))
+	if dist == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	versions, err := dist.Candidates()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var rcs []string
+	for i := len(versions) - 1; i >= 0; i-- {
+		if rc := versions[i].String(); strings.HasPrefix(rc, toComplete) {
+			rcs = append(rcs, rc)
+		}
+	}
+
+	return rcs, cobra.ShellCompDirectiveNoFileComp
+}
+
+var listCmd = &cobra.Command{
+	Use:       "list <project>",
+	Short:     "List release candidates of project under dist",
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: projectNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dist := dist(args[0])
+		if dist == nil {
+			return fmt.Errorf("project %s unsupported", args[0])
+		}
+
+		versions, err := dist.Candidates()
+		if err != nil {
+			return err
+		}
+
+		for _, v := range versions {
+			fmt.Println(v)
+		}
+
+		return nil
+	},
+}
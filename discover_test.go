@@ -0,0 +1,82 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const distIndex = `<html><head><title>dev/apisix - Revision 53000</title></head>
+<body>
+ <h2>dev/apisix - Revision 53000</h2>
+ <ul>
+  <li><a href="../">..</a></li>
+  <li><a href="2.13.0/">2.13.0/</a></li>
+  <li><a href="3.0.0-beta/">3.0.0-beta/</a></li>
+  <li><a href="KEYS">KEYS</a></li>
+  <li><a href="apisix-dashboard-2.10.1/">apisix-dashboard-2.10.1/</a></li>
+  <li><a href="apisix-dashboard-2.11.0/">apisix-dashboard-2.11.0/</a></li>
+  <li><a href="apisix-ingress-controller-1.4.0/">apisix-ingress-controller-1.4.0/</a></li>
+ </ul>
+</body></html>`
+
+func TestListing(t *testing.T) {
+	want := []string{"2.13.0", "3.0.0-beta", "apisix-dashboard-2.10.1", "apisix-dashboard-2.11.0", "apisix-ingress-controller-1.4.0"}
+	if got := listing(distIndex); !reflect.DeepEqual(got, want) {
+		t.Errorf("listing() = %v, want %v", got, want)
+	}
+}
+
+func TestCandidate_MatchPackage(t *testing.T) {
+	tests := []struct {
+		name      string
+		candidate Candidate
+		dirs      []string
+		want      []string
+	}{
+		{
+			name:      "apisix bare version",
+			candidate: Candidate{pkg: pkgAPISix, naming: apisixNaming},
+			dirs:      listing(distIndex),
+			want:      []string{"2.13.0", "3.0.0-beta"},
+		},
+		{
+			name:      "dashboard sub-project",
+			candidate: Candidate{pkg: pkgAPISixDashboard, naming: dashboardNaming},
+			dirs:      listing(distIndex),
+			want:      []string{"2.10.1", "2.11.0"},
+		},
+		{
+			name:      "rc number in directory",
+			candidate: Candidate{pkg: pkgAPISixDashboard, naming: Naming{Dir: "{pkg}-{major}.{minor}.{patch}-{rc}"}},
+			dirs:      []string{"apisix-dashboard-2.11.0-rc2", "apisix-dashboard-2.11.0"},
+			want:      []string{"2.11.0-rc2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, dir := range tt.dirs {
+				if v, ok := tt.candidate.MatchPackage(dir); ok {
+					got = append(got, v.String())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchPackage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -15,26 +15,16 @@
 package main
 
 import (
-	"archive/tar"
 	"bytes"
-	"compress/gzip"
-	"crypto/sha512"
 	"fmt"
-	"io"
-	"log"
-	"net"
-	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
-	"github.com/ProtonMail/go-crypto/openpgp/armor"
-	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/jinzhu/copier"
 
-	"github.com/parnurzeal/gorequest"
 	"github.com/spf13/cobra"
 )
 
@@ -47,6 +37,64 @@ const (
 	pkgAPISixGoPluginRunner    = "apisix-go-plugin-runner"
 )
 
+var (
+	// apisixNaming apisix dist directory is the bare version
+	apisixNaming = Naming{
+		Dir:     "{version}",
+		Src:     defaultNaming.Src,
+		Tag:     defaultNaming.Tag,
+		Branch:  defaultNaming.Branch,
+		Anchor:  defaultNaming.Anchor,
+		Archive: defaultNaming.Archive,
+		Top:     defaultNaming.Top,
+	}
+
+	// dashboardNaming dashboard tags with v prefix
+	dashboardNaming = Naming{
+		Dir:     defaultNaming.Dir,
+		Src:     defaultNaming.Src,
+		Tag:     "v{version}",
+		Branch:  defaultNaming.Branch,
+		Anchor:  defaultNaming.Anchor,
+		Archive: defaultNaming.Archive,
+		Top:     defaultNaming.Top,
+	}
+
+	// goPluginRunnerNaming go-plugin-runner without package prefix, release branch per version
+	goPluginRunnerNaming = Naming{
+		Dir:     defaultNaming.Dir,
+		Src:     "{pkg}-{version}",
+		Tag:     "v{version}",
+		Branch:  "release/{version}",
+		Anchor:  defaultNaming.Anchor,
+		Archive: defaultNaming.Archive,
+		Top:     defaultNaming.Top,
+	}
+)
+
+// licenseCheck license header check job, which every project runs by skywalking-eyes
+const licenseCheck = "check-license"
+
+// withRequiredCI CI checks of project definition extended by flag
+func withRequiredCI(checks ...string) []string {
+	var required []string
+	seen := make(map[string]bool)
+	for _, name := range append(checks, requiredCI...) {
+		if !seen[name] {
+			seen[name] = true
+			required = append(required, name)
+		}
+	}
+
+	return required
+}
+
+// candidateVersion parse candidate flag, which validated by sixerPreRunE
+func candidateVersion() Semver {
+	v, _ := ParseSemver(candidate)
+	return v
+}
+
 // A Dist repo include package and its asc sha512
 type Dist struct {
 	Candidate
@@ -55,7 +103,41 @@ type Dist struct {
 	repo      string
 	commit    string
 	blob      string // release-note branch, like v1.4.0, only work for links
-	trimTag   bool   // trim tag's suffix .0 or not
+	dir       string // directory which package files download into
+	ws        *Workspace
+	keys      string   // KEYS file verifies signature instead of exported announcer key
+	checks    []string // archive checks of project, by registered name
+	api       string   // GitHub API base URL, empty disables API checks
+	gitDir    string   // local clone which release tag and commit read from
+	offline   bool     // artifacts read from local directory without any request
+	verifyGit bool     // verify signatures of release tag and commit
+	required  []string // CI checks which must have succeeded on commit
+	generated []string // patterns of files generated when packaging, not in git
+
+	compareGitHub     bool   // compare src archive with GitHub archive of tag
+	githubArchiveFile string // local GitHub archive of tag, downloaded if empty
+
+	diffPrevious bool     // diff src archive against previous release
+	previousFile string   // local src archive of previous release, looked up if empty
+	releaseArea  string   // dist area which voted releases move into
+	archiveArea  string   // archive area of all releases
+	supported    []string // major.minor lines kept in release area besides the newest
+
+	downloadsArea string // downloads site mirroring release area
+	report        *Report
+}
+
+// path package file's location under download directory
+func (d *Dist) path(name string) string {
+	return filepath.Join(d.dir, name)
+}
+
+// Report verification report of candidate
+func (d *Dist) Report() *Report {
+	d.report.Project = d.repo
+	d.report.Candidate = d.rc.String()
+
+	return d.report
 }
 
 func (d *Dist) validAttrs() (bool, error) {
@@ -66,140 +148,98 @@ func (d *Dist) validAttrs() (bool, error) {
 	return true, nil
 }
 
-// ValidGitHubLinks validate github links
-func (d *Dist) ValidGitHubLinks() error {
-	tag := d.rc
-	if d.trimTag {
-		tag = strings.TrimSuffix(tag, ".0")
-	}
+// github validator of candidate, shares linker of dist
+func (d *Dist) github() *GitHub {
 	git := &Git{
 		Repo:    d.repo,
 		Commit:  d.commit,
-		Release: d.rc,
+		Release: d.rc.Version(),
 		Blob:    d.blob,
-		Tag:     tag,
+		Tag:     d.Tag(),
+		Branch:  d.Branch(),
+		Anchor:  d.Anchor(),
 	}
 
 	github, _ := NewGitHub(git)
-	if err := github.ValidLinks(); err != nil {
-		return err
-	}
+	github.Linker = d.Linker
+	github.report = d.report
+	return github
+}
 
-	return nil
+// githubAPI API of repository sharing linker of dist
+func (d *Dist) githubAPI() *GitHubAPI {
+	api := NewGitHubAPI(d.api, d.repo)
+	api.Linker = d.Linker
+	return api
 }
 
-// ValidDistLinks validate dist links
-func (d *Dist) ValidDistLinks() error {
-	links := []string{d.PackageLink(), d.SrcLink(), d.SrcAscLink(), d.SrcSha512Link()}
-	for _, link := range links {
-		if ok, err := d.Linker.Head(link); err != nil {
-			log.Printf("dist %s validate bad ❌ %s\n", link, err)
-			return err
-		} else if ok {
-			log.Printf("dist %s validate ok ✅\n", link)
-		} else {
-			log.Printf("dist %s validate bad ❌\n", link)
-		}
+// ValidGitHubAPI verify commit, branch, tag, CHANGELOG, CI status and
+// milestone via GitHub API, skipped if API base URL is empty
+func (d *Dist) ValidGitHubAPI() error {
+	if d.api == "" {
+		return nil
 	}
 
-	return nil
-}
-
-// ValidAllLinks validate URL links, include package and its src asc sha512
-func (d *Dist) ValidAllLinks() error {
-	if err := d.ValidGitHubLinks(); err != nil {
+	github, api := d.github(), d.githubAPI()
+	if err := github.ValidAPI(api); err != nil {
 		return err
 	}
-
-	if err := d.ValidDistLinks(); err != nil {
+	if err := github.ValidCI(api, d.required); err != nil {
 		return err
 	}
 
-	return nil
+	return github.ValidMilestone(api)
 }
 
-func (d *Dist) fetchSrcTgz() error {
-	if f, err := os.Stat(d.srcTgz()); err != nil && !os.IsNotExist(err) {
-		return err
-	} else if f != nil {
-		return nil
+// distChecks dist links to validate
+func (d *Dist) distChecks() []linkCheck {
+	var checks []linkCheck
+	for _, link := range []string{d.PackageLink(), d.SrcLink(), d.SrcAscLink(), d.SrcSha512Link()} {
+		checks = append(checks, linkCheck{kind: "dist", link: link})
 	}
 
-	var err error
-	r := gorequest.New()
-	sa := r.Timeout(time.Duration(d.timeout) * time.Second)
-
-	sa.Get(d.SrcLink()).EndBytes(func(res gorequest.Response, body []byte, errs []error) {
-		for _, e := range errs {
-			if e != nil {
-				err = e
-			}
-		}
-		if err != nil {
-			return
-		}
-
-		if res.StatusCode != http.StatusOK {
-			err = fmt.Errorf("non-expected response status %s", res.Status)
-			return
-		}
-
-		if len(body) == 0 {
-			err = fmt.Errorf("response body size zero")
-			return
-		}
-
-		err = os.WriteFile(d.srcTgz(), body, 0644)
-	})
-
-	return err
+	return checks
 }
 
-func (d *Dist) fetchSrcTgzSha512() error {
-	var err error
-
-	_, err = os.Stat(d.srcTgzSha512())
-	if os.IsNotExist(err) {
-		err = nil
-		goto download
-	}
-	return nil
+// ValidGitHubLinks validate github links
+func (d *Dist) ValidGitHubLinks() error {
+	return d.github().ValidLinks()
+}
 
-download:
-	r := gorequest.New()
-	sa := r.Timeout(time.Duration(d.timeout) * time.Second)
+// ValidDistLinks validate dist links
+func (d *Dist) ValidDistLinks() error {
+	return d.Linker.HeadAll(d.distChecks(), d.report)
+}
 
-	sa.Get(d.SrcSha512Link()).EndBytes(func(res gorequest.Response, body []byte, errs []error) {
-		for _, e := range errs {
-			if e != nil {
-				err = e
-			}
-		}
-		if err != nil {
-			return
-		}
+// ValidAllLinks validate URL links concurrently, include github links,
+// package and its src asc sha512
+func (d *Dist) ValidAllLinks() error {
+	checks := append(d.github().checks(), d.distChecks()...)
+	return d.Linker.HeadAll(checks, d.report)
+}
 
-		if res.StatusCode != http.StatusOK {
-			err = fmt.Errorf("non-expected response status %s", res.Status)
-			return
+// download link into workspace, tracks files it creates
+func (d *Dist) download(link, name string) error {
+	filename := d.path(name)
+	for _, f := range []string{filename, filename + partSuffix, filename + metaSuffix} {
+		if err := d.ws.Track(f); err != nil {
+			return err
 		}
+	}
 
-		if len(body) == 0 {
-			err = fmt.Errorf("response checksum size zero")
-			return
-		}
+	return d.Linker.Download(link, filename)
+}
 
-		err = os.WriteFile(d.srcTgzSha512(), body, 0644)
-		if err != nil {
-			return
-		}
-	})
+func (d *Dist) fetchSrc() error {
+	return d.download(d.SrcLink(), d.srcArchive())
+}
 
-	return err
+func (d *Dist) fetchSrcSha512() error {
+	return d.download(d.SrcSha512Link(), d.srcSha512())
 }
 
 func (d *Dist) validKey() (bool, error) {
-	key, err := os.Open(keyFilename)
+	key, err := os.Open(d.path(keyFilename))
 	if err != nil {
 		return false, err
 	}
@@ -219,7 +259,7 @@ func (d *Dist) validKey() (bool, error) {
 		return false, fmt.Errorf("there's no primary identity")
 	}
 
-	return strings.HasPrefix(id.Name, d.announcer), nil
+	return isAnnouncerName(id.UserId.Name, d.announcer), nil
 }
 
 func (d *Dist) fetchKey() error {
@@ -227,7 +267,7 @@ func (d *Dist) fetchKey() error {
 		return err
 	}
 
-	if _, err := os.Stat(keyFilename); err != nil {
+	if _, err := os.Stat(d.path(keyFilename)); err != nil {
 		if !os.IsNotExist(err) {
 			return err
 		}
@@ -237,13 +277,21 @@ func (d *Dist) fetchKey() error {
 		if ok, err := d.validKey(); err != nil {
 			return err
 		} else if !ok {
+			// never overwrite key of user, only the one exported earlier
+			if !d.ws.Tracked(d.path(keyFilename)) {
+				return fmt.Errorf("%s holds key of another identity, not exported by sixer, remove it or use another workdir", d.path(keyFilename))
+			}
 			goto export
 		}
 		return nil
 	}
 
 export:
-	cmd := exec.Command("gpg", "--armor", "--output", keyFilename, "--yes", "--export", d.announcer)
+	if err := d.ws.Track(d.path(keyFilename)); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("gpg", "--armor", "--output", d.path(keyFilename), "--yes", "--export", d.announcer)
 	if err := cmd.Run(); err != nil {
 		return err
 	}
@@ -254,292 +302,306 @@ export:
 	return nil
 }
 
-func (d *Dist) fetchSrcTgzAsc() error {
-	var err error
-
-	_, err = os.Stat(d.srcTgzAsc())
-	if os.IsNotExist(err) {
-		err = nil
-		goto download
-	}
-	return nil
-
-download:
-	r := gorequest.New()
-	sa := r.Timeout(time.Duration(d.timeout) * time.Second)
-
-	sa.Get(d.SrcAscLink()).EndBytes(func(res gorequest.Response, body []byte, errs []error) {
-		for _, e := range errs {
-			if e != nil {
-				err = e
-			}
-		}
-		if err != nil {
-			return
-		}
-
-		if res.StatusCode != http.StatusOK {
-			err = fmt.Errorf("non-expected response status %s", res.Status)
-			return
-		}
-
-		if len(body) == 0 {
-			err = fmt.Errorf("response signature size zero")
-			return
-		}
-
-		err = os.WriteFile(d.srcTgzAsc(), body, 0644)
-	})
-
-	return err
+func (d *Dist) fetchSrcAsc() error {
+	return d.download(d.SrcAscLink(), d.srcAsc())
 }
 
-func (d *Dist) checksum(src []byte, body []byte) (bool, error) {
-	var err error
+// expectedChecksum read sha512 from checksum file, like "<sum>  <filename>"
+func (d *Dist) expectedChecksum() (string, error) {
+	body, err := os.ReadFile(d.path(d.srcSha512()))
+	if err != nil {
+		return "", err
+	}
 
 	sums := bytes.Split(body, []byte("  "))
 	if len(sums) != 2 {
-		return false, fmt.Errorf("invalid checksum body")
+		return "", fmt.Errorf("invalid checksum body")
 	}
 
-	if len(src) == 0 {
-		filename := strings.TrimSpace(string(sums[1]))
-		src, err = os.ReadFile(filename)
-		if err != nil {
-			return false, err
-		}
-	}
+	return strings.TrimSpace(string(sums[0])), nil
+}
 
-	hash := sha512.New()
-	hash.Write(src)
-	checksum := fmt.Sprintf("%x", hash.Sum(nil))
-	ret := strings.Compare(string(sums[0]), checksum)
+// keyRing public keys which verify signature, KEYS file if specified,
+// otherwise the exported announcer key
+func (d *Dist) keyRing() (openpgp.EntityList, error) {
+	if d.keys != "" {
+		return readKeyRing(d.keys)
+	}
 
-	return ret == 0, nil
+	return readKeyRing(d.path(keyFilename))
 }
 
-func (d *Dist) signature(src []byte, sign *os.File, key *os.File) error {
-	block, err := armor.Decode(sign)
+// signatureCheck check against asc file, nil with error if it can't start
+func (d *Dist) signatureCheck() (*signatureCheck, *os.File, error) {
+	keyring, err := d.keyRing()
 	if err != nil {
-		return err
-	}
-
-	if block.Type != "PGP SIGNATURE" {
-		return fmt.Errorf("not an armor signature")
+		return nil, nil, err
 	}
 
-	pkg, err := packet.Read(block.Body)
+	sign, err := os.Open(d.path(d.srcAsc()))
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	signature, ok := pkg.(*packet.Signature)
-	if !ok {
-		return fmt.Errorf("not a valid signature file")
-	}
+	return newSignatureCheck(keyring, sign), sign, nil
+}
 
-	kblock, err := armor.Decode(key)
-	if err != nil {
-		return err
-	}
+// A distScan represents outcome of the one pass over source package
+type distScan struct {
+	*archiveScan
+	signErr error
+}
 
-	if kblock.Type != "PGP PUBLIC KEY BLOCK" {
-		return fmt.Errorf("not an armored public key")
+// scan read source package once, verify signature if withSign,
+// entries dispatched to visitors
+func (d *Dist) scan(withSign bool, visitors ...entryVisitor) (*distScan, error) {
+	var sign *signatureCheck
+	var signErr error
+	if withSign {
+		var asc *os.File
+		if sign, asc, signErr = d.signatureCheck(); asc != nil {
+			defer asc.Close()
+		}
 	}
 
-	kpkg, err := packet.Read(kblock.Body)
+	s, err := scanArchive(d.path(d.srcArchive()), sign, visitors)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	pubKey, ok := kpkg.(*packet.PublicKey)
-	if !ok {
-		return fmt.Errorf("not a valid public key file")
+	if sign != nil {
+		signErr = sign.err
+		if signErr == nil && d.announcer != "" && !signedBy(sign.signer, d.announcer) {
+			signErr = fmt.Errorf("signed by %s, not announcer %s", sign.signer.PrimaryKey.KeyIdString(), d.announcer)
+		}
 	}
 
-	hash := signature.Hash.New()
-	_, err = hash.Write(src)
+	return &distScan{archiveScan: s, signErr: signErr}, nil
+}
+
+// validChecksum compare computed sha512 with checksum file
+func (d *Dist) validChecksum(s *distScan) (bool, error) {
+	sum, err := d.expectedChecksum()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return pubKey.VerifySignature(hash, signature)
+	return strings.EqualFold(sum, s.artifact.SHA512), nil
 }
 
 // ValidChecksum validate from sha512 checksum file
 func (d *Dist) ValidChecksum() (bool, error) {
-	checksum, err := os.ReadFile(d.srcTgzSha512())
-	if err != nil {
-		return false, err
-	}
-
-	src, err := os.ReadFile(d.srcTgz())
+	s, err := d.scan(false)
 	if err != nil {
 		return false, err
 	}
 
-	return d.checksum(src, checksum)
+	return d.validChecksum(s)
 }
 
 // ValidSignature validate from asc file
 func (d *Dist) ValidSignature() (bool, error) {
-	src, err := os.ReadFile(d.srcTgz())
+	s, err := d.scan(true)
 	if err != nil {
 		return false, err
 	}
 
-	sign, err := os.Open(d.srcTgzAsc())
-	if err != nil {
-		return false, err
+	return s.signErr == nil, s.signErr
+}
+
+// startChecks create archive checks of project definition, podlings always
+// run incubator check, each begins with the source package
+func (d *Dist) startChecks() ([]*runningCheck, []entryVisitor, error) {
+	names := d.checks
+	if d.incubating && !contains(names, checkIncubator) {
+		names = append(append([]string{}, names...), checkIncubator)
+	}
+	if d.compareGitHub && !contains(names, checkGitHubArchive) {
+		names = append(append([]string{}, names...), checkGitHubArchive)
 	}
-	defer sign.Close()
 
-	key, err := os.Open(keyFilename)
-	if err != nil {
-		return false, err
+	var running []*runningCheck
+	var visitors []entryVisitor
+	for _, name := range names {
+		c, err := newArchiveCheck(name, d)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rc := &runningCheck{name: name, check: c}
+		rc.err = c.Begin(d.srcArchive())
+		running = append(running, rc)
+		visitors = append(visitors, rc.visit)
+	}
+
+	return running, visitors, nil
+}
+
+// recordChecks record results of archive checks, which cover entries read
+// before corruption if any
+func (d *Dist) recordChecks(running []*runningCheck, entriesErr error) {
+	if entriesErr != nil {
+		d.report.Record("archive corrupted", false, entriesErr)
 	}
-	defer key.Close()
 
-	err = d.signature(src, sign, key)
-	return err == nil, err
+	for _, rc := range running {
+		for _, res := range rc.results() {
+			d.report.RecordResult(res)
+		}
+	}
 }
 
-// CheckExtras check LICENSE NOTICE exist or not
+// CheckExtras run archive checks of project, like LICENSE NOTICE exist or not
 func (d *Dist) CheckExtras() (bool, error) {
-	f, err := os.Open(d.srcTgz())
+	running, visitors, err := d.startChecks()
 	if err != nil {
 		return false, err
 	}
-	defer f.Close()
 
-	gzf, err := gzip.NewReader(f)
+	s, err := d.scan(false, visitors...)
 	if err != nil {
 		return false, err
 	}
-	defer gzf.Close()
 
-	licFound := false // LICENSE
-	notFound := false // NOTICE
+	d.recordChecks(running, s.entriesErr)
+	return s.entriesErr == nil, s.entriesErr
+}
 
-	tf := tar.NewReader(gzf)
-	for {
-		hdr, err := tf.Next()
-		if err == io.EOF {
-			break
-		}
+// Fetch export key and fetch package files concurrently,
+// failures recorded in the fixed order, returns the first one
+func (d *Dist) Fetch() error {
+	steps := []struct {
+		item  string
+		fetch func() error
+	}{
+		{"dist fetch key", d.fetchKey},
+		{"dist fetch src", d.fetchSrc},
+		{"dist fetch src sha512", d.fetchSrcSha512},
+		{"dist fetch src asc", d.fetchSrcAsc},
+	}
+
+	errs := make([]error, len(steps))
+	parallel(d.Linker.workers, len(steps), func(i int) {
+		errs[i] = steps[i].fetch()
+	})
 
-		switch hdr.Typeflag {
-		case tar.TypeReg:
-			switch hdr.Name {
-			case "LICENSE", "./LICENSE":
-				licFound = true
-				log.Println("LICENSE ok ✅")
-			case "NOTICE", "./NOTICE":
-				notFound = true
-				log.Println("NOTICE ok ✅")
-			}
+	var first error
+	for i, step := range steps {
+		if errs[i] == nil {
+			continue
+		}
+		d.report.Record(step.item, false, errs[i])
+		if first == nil {
+			first = errs[i]
 		}
 	}
 
-	if !licFound {
-		log.Println("LICENSE bad ❌")
-	}
-	if !notFound {
-		log.Println("NOTICE bad ❌")
-	}
-
-	return true, nil
+	return first
 }
 
-// Fetch export key and fetch package files
-func (d *Dist) Fetch() error {
-	if err := d.fetchKey(); err != nil {
-		log.Printf("dist fetch key bad ❌ %s\n", err)
-		return err
+// Clean cleans files created by sixer, without workspace only download files
+func (d *Dist) Clean() error {
+	if d.ws != nil {
+		return d.ws.Clean()
 	}
 
-	if err := d.fetchSrcTgz(); err != nil {
-		log.Printf("dist fetch src tgz bad ❌ %s\n", err)
+	if err := removeDownload(d.path(d.srcSha512())); err != nil {
 		return err
 	}
 
-	if err := d.fetchSrcTgzSha512(); err != nil {
-		log.Printf("dist fetch src tgz sha512 bad ❌ %s\n", err)
+	if err := removeDownload(d.path(d.srcAsc())); err != nil {
 		return err
 	}
 
-	if err := d.fetchSrcTgzAsc(); err != nil {
-		log.Printf("dist fetch src tgz asc bad ❌ %s\n", err)
-		return err
+	return removeDownload(d.path(d.srcArchive()))
+}
+
+// Verify package
+// 1. check links
+// 2. download packages
+// 3. read source package once: checksum, signature, then archive checks
+func (d *Dist) Verify() {
+	running, visitors, err := d.startChecks()
+	if err != nil {
+		d.report.Record("dist archive checks", false, err)
 	}
 
-	return nil
+	s, err := d.scan(true, visitors...)
+	if err != nil {
+		d.report.Record("dist read src", false, err)
+		return
+	}
+	d.report.AddArtifact(s.artifact)
+
+	ok, err := d.validChecksum(s)
+	d.report.Record("dist validate checksum", ok, err)
+
+	d.report.Record("dist validate signature", s.signErr == nil, s.signErr)
+
+	d.recordChecks(running, s.entriesErr)
 }
 
-// Clean cleans download files
-func (d *Dist) Clean() error {
-	if err := os.Remove(d.srcTgzSha512()); err != nil && !os.IsNotExist(err) {
-		return err
-	}
+// projectNames supported project commands
+var projectNames = []string{"apisix", "dashboard", "ingress-controller", "go-plugin-runner"}
 
-	if err := os.Remove(d.srcTgzAsc()); err != nil && !os.IsNotExist(err) {
-		return err
+// projects definitions of projects by command name, a podling definition sets
+// its Incubator project and incubating with -incubating naming
+var projects = map[string]func() *Dist{
+	"apisix":             NewAPISixDist,
+	"dashboard":          NewDashboardDist,
+	"ingress-controller": NewIngressControllerDist,
+	"go-plugin-runner":   NewGoPluginRunnerDist,
+}
+
+func dist(name string) *Dist {
+	newDist, ok := projects[name]
+	if !ok {
+		return nil
 	}
 
-	if err := os.Remove(d.srcTgz()); err != nil && !os.IsNotExist(err) {
-		return err
+	return newDist()
+}
+
+// runDist dist of project command, shared by its pre-run, run and post-run
+var runDist *Dist
+
+// distPreRunE build dist of project command once, then validate links and GitHub API
+func distPreRunE(cmd *cobra.Command, args []string) error {
+	runDist = dist(cmd.Name())
+	if runDist == nil {
+		return fmt.Errorf("project %s unsupported", cmd.Name())
 	}
 
-	if err := os.Remove(keyFilename); err != nil && !os.IsNotExist(err) {
+	if err := runDist.ValidAllLinks(); err != nil {
 		return err
 	}
 
-	return nil
+	return runDist.ValidGitHubAPI()
 }
 
-// Verify package
-// 1. check links
-// 2. download packages
-// 3. verify checksum and signature
-// 4. untar then check LICENSE and NOTICE
-func (d *Dist) Verify() {
-	if ok, err := d.ValidChecksum(); err != nil {
-		log.Printf("dist validate checksum bad ❌ %s\n", err)
-	} else if ok {
-		log.Println("dist validate checksum ok ✅")
-	} else {
-		log.Println("dist validate checksum bad ❌")
+// distRunE fetch then verify package files, release tag and commit, then diff
+// against previous release; summary printed and report saved if asked
+func distRunE(cmd *cobra.Command, args []string) error {
+	if err := runDist.Fetch(); err != nil {
+		return err
 	}
 
-	if ok, err := d.ValidSignature(); err != nil {
-		log.Printf("dist validate signature bad ❌ %s", err)
-	} else if ok {
-		log.Println("dist validate signature ok ✅")
-	} else {
-		log.Println("dist validate signature bad ❌")
-	}
+	runDist.Verify()
+	runDist.VerifyGit()
+	runDist.DiffPrevious()
 
-	if _, err := d.CheckExtras(); err != nil {
-		log.Printf("dist check extras bad ❌ %s\n", err)
-	}
+	report := runDist.Report()
+	report.Summary(os.Stdout)
+	return writeReports(reportFile, []*Report{report})
 }
 
-func dist(name string) *Dist {
-	var dist *Dist
-	switch name {
-	case "dashboard":
-		dist = NewDashboardDist()
-	case "apisix":
-		dist = NewAPISixDist()
-	case "go-plugin-runner":
-		dist = NewGoPluginRunnerDist()
-	case "ingress-controller":
-		dist = NewIngressControllerDist()
-	default:
-		dist = nil
+// distPostRunE clean package files unless keep
+func distPostRunE(cmd *cobra.Command, args []string) error {
+	if keep {
+		return nil
 	}
 
-	return dist
+	return runDist.Clean()
 }
 
 var linkCmd = &cobra.Command{
@@ -564,6 +626,7 @@ var loaderCmd = &cobra.Command{
 	Short: "download package files",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if dist := dist(cmd.Parent().Name()); dist != nil {
+			keep = true
 			return dist.Fetch()
 		}
 
@@ -588,42 +651,47 @@ func NewAPISixDist() *Dist {
 	return &Dist{
 		Candidate: Candidate{
 			pkg:       pkgAPISix,
-			rc:        candidate,
-			sub:       false,
+			rc:        candidateVersion(),
 			pkgPrefix: prefixApache,
+			naming:    apisixNaming,
+			project:   projectAPISix,
 		},
 		announcer: announcer,
 		repo:      pkgAPISix,
 		commit:    commitID,
 		blob:      blob,
-		trimTag:   true,
-		Linker: Linker{
-			timeout: timeout,
-		},
+		dir:       workspace.Dir(),
+		ws:        workspace,
+		Linker:    newLinker(),
+		checks:    defaultChecks,
+		api:       githubAPI,
+		keys:      keysFile,
+		gitDir:    gitDir,
+		verifyGit: verifyGit || gitDir != "",
+		required:  withRequiredCI(licenseCheck),
+		report:    &Report{},
+
+		compareGitHub:     compareGitHub || githubArchiveFile != "",
+		githubArchiveFile: githubArchiveFile,
+		generated:         withGenerated(),
+
+		diffPrevious: diffPrevious || previousFile != "",
+		previousFile: previousFile,
+		releaseArea:  releaseArea,
+		archiveArea:  archiveArea,
+		supported:    withSupported("2.13"),
+
+		downloadsArea: downloadsArea,
 	}
 }
 
 var apiSixCmd = &cobra.Command{
-	Use:              "apisix",
-	Short:            "apisix package verifier",
-	PersistentPreRun: sixerPreRun,
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewAPISixDist()
-		return dist.ValidAllLinks()
-	},
-	RunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewAPISixDist()
-		if err := dist.Fetch(); err != nil {
-			return err
-		}
-
-		dist.Verify()
-		return nil
-	},
-	PostRunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewAPISixDist()
-		return dist.Clean()
-	},
+	Use:               "apisix",
+	Short:             "apisix package verifier",
+	PersistentPreRunE: sixerPreRunE,
+	PreRunE:           distPreRunE,
+	RunE:              distRunE,
+	PostRunE:          distPostRunE,
 }
 
 // NewDashboardDist dashboard dist
@@ -631,126 +699,140 @@ func NewDashboardDist() *Dist {
 	return &Dist{
 		Candidate: Candidate{
 			pkg:       pkgAPISixDashboard,
-			rc:        candidate,
-			sub:       true,
+			rc:        candidateVersion(),
 			pkgPrefix: prefixApache,
+			naming:    dashboardNaming,
+			project:   projectAPISix,
 		},
 		announcer: announcer,
 		repo:      pkgAPISixDashboard,
 		commit:    commitID,
-		trimTag:   true,
-		Linker: Linker{
-			timeout: timeout,
-		},
+		dir:       workspace.Dir(),
+		ws:        workspace,
+		Linker:    newLinker(),
+		checks:    defaultChecks,
+		api:       githubAPI,
+		keys:      keysFile,
+		gitDir:    gitDir,
+		verifyGit: verifyGit || gitDir != "",
+		required:  withRequiredCI(licenseCheck),
+		report:    &Report{},
+
+		compareGitHub:     compareGitHub || githubArchiveFile != "",
+		githubArchiveFile: githubArchiveFile,
+		generated:         withGenerated("/web/dist"),
+
+		diffPrevious: diffPrevious || previousFile != "",
+		previousFile: previousFile,
+		releaseArea:  releaseArea,
+		archiveArea:  archiveArea,
+		supported:    withSupported(),
+
+		downloadsArea: downloadsArea,
 	}
 }
 
 var dashboardCmd = &cobra.Command{
-	Use:              "dashboard",
-	Short:            "apisix dashboard package verifier",
-	PersistentPreRun: sixerPreRun,
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewDashboardDist()
-		return dist.ValidAllLinks()
-	},
-	RunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewDashboardDist()
-		if err := dist.Fetch(); err != nil {
-			return err
-		}
-
-		dist.Verify()
-		return nil
-	},
-	PostRunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewDashboardDist()
-		return dist.Clean()
-	},
+	Use:               "dashboard",
+	Short:             "apisix dashboard package verifier",
+	PersistentPreRunE: sixerPreRunE,
+	PreRunE:           distPreRunE,
+	RunE:              distRunE,
+	PostRunE:          distPostRunE,
 }
 
 // NewIngressControllerDist ingress controller dist
 func NewIngressControllerDist() *Dist {
-	ip := net.ParseIP("::1/128")
-	ip.IsLinkLocalMulticast()
 	return &Dist{
 		Candidate: Candidate{
 			pkg:       pkgAPISixIngressController,
-			rc:        candidate,
-			sub:       true,
+			rc:        candidateVersion(),
 			pkgPrefix: prefixApache,
+			naming:    defaultNaming,
+			project:   projectAPISix,
 		},
 		announcer: announcer,
 		repo:      pkgAPISixIngressController,
 		commit:    commitID,
 		blob:      blob,
-		trimTag:   true,
-		Linker: Linker{
-			timeout: timeout,
-		},
+		dir:       workspace.Dir(),
+		ws:        workspace,
+		Linker:    newLinker(),
+		checks:    defaultChecks,
+		api:       githubAPI,
+		keys:      keysFile,
+		gitDir:    gitDir,
+		verifyGit: verifyGit || gitDir != "",
+		required:  withRequiredCI(licenseCheck),
+		report:    &Report{},
+
+		compareGitHub:     compareGitHub || githubArchiveFile != "",
+		githubArchiveFile: githubArchiveFile,
+		generated:         withGenerated(),
+
+		diffPrevious: diffPrevious || previousFile != "",
+		previousFile: previousFile,
+		releaseArea:  releaseArea,
+		archiveArea:  archiveArea,
+		supported:    withSupported(),
+
+		downloadsArea: downloadsArea,
 	}
 }
 
 var ingressControllerCmd = &cobra.Command{
-	Use:              "ingress-controller",
-	Short:            "apisix ingress controller package verifier",
-	PersistentPreRun: sixerPreRun,
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewIngressControllerDist()
-		return dist.ValidAllLinks()
-	},
-	RunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewIngressControllerDist()
-		if err := dist.Fetch(); err != nil {
-			return err
-		}
-
-		dist.Verify()
-		return nil
-	},
-	PostRunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewIngressControllerDist()
-		return dist.Clean()
-	},
+	Use:               "ingress-controller",
+	Short:             "apisix ingress controller package verifier",
+	PersistentPreRunE: sixerPreRunE,
+	PreRunE:           distPreRunE,
+	RunE:              distRunE,
+	PostRunE:          distPostRunE,
 }
 
 // NewGoPluginRunnerDist go-plugin-runner dist
 func NewGoPluginRunnerDist() *Dist {
 	return &Dist{
 		Candidate: Candidate{
-			pkg: pkgAPISixGoPluginRunner,
-			rc:  candidate,
-			sub: true,
+			pkg:     pkgAPISixGoPluginRunner,
+			rc:      candidateVersion(),
+			naming:  goPluginRunnerNaming,
+			project: projectAPISix,
 		},
 		announcer: announcer,
 		repo:      pkgAPISixGoPluginRunner,
 		commit:    commitID,
-		Linker: Linker{
-			timeout: timeout,
-		},
+		dir:       workspace.Dir(),
+		ws:        workspace,
+		Linker:    newLinker(),
+		checks:    defaultChecks,
+		api:       githubAPI,
+		keys:      keysFile,
+		gitDir:    gitDir,
+		verifyGit: verifyGit || gitDir != "",
+		required:  withRequiredCI(licenseCheck),
+		report:    &Report{},
+
+		compareGitHub:     compareGitHub || githubArchiveFile != "",
+		githubArchiveFile: githubArchiveFile,
+		generated:         withGenerated(),
+
+		diffPrevious: diffPrevious || previousFile != "",
+		previousFile: previousFile,
+		releaseArea:  releaseArea,
+		archiveArea:  archiveArea,
+		supported:    withSupported(),
+
+		downloadsArea: downloadsArea,
 	}
 }
 
 var goPluginRunnerCmd = &cobra.Command{
-	Use:              "go-plugin-runner",
-	Short:            "apisix go-plugin-runner package verifier",
-	PersistentPreRun: sixerPreRun,
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewGoPluginRunnerDist()
-		return dist.ValidAllLinks()
-	},
-	RunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewGoPluginRunnerDist()
-		if err := dist.Fetch(); err != nil {
-			return err
-		}
-
-		dist.Verify()
-		return nil
-	},
-	PostRunE: func(cmd *cobra.Command, args []string) error {
-		dist := NewGoPluginRunnerDist()
-		return dist.Clean()
-	},
+	Use:               "go-plugin-runner",
+	Short:             "apisix go-plugin-runner package verifier",
+	PersistentPreRunE: sixerPreRunE,
+	PreRunE:           distPreRunE,
+	RunE:              distRunE,
+	PostRunE:          distPostRunE,
 }
 
 func init() {
@@ -761,8 +843,14 @@ func init() {
 	_ = copier.Copy(load1, loaderCmd)
 	var clean1 = &cobra.Command{}
 	_ = copier.Copy(clean1, cleanCmd)
-	apiSixCmd.AddCommand(link1, load1, clean1)
+	var released1 = &cobra.Command{}
+	_ = copier.Copy(released1, releasedCmd)
+	bindReleasedFlags(released1.Flags())
+	apiSixCmd.AddCommand(link1, load1, clean1, released1)
 	bindExtraFlags(apiSixCmd.Flags())
+	bindSignFlags(apiSixCmd.Flags())
+	bindArchiveFlags(apiSixCmd.Flags())
+	bindReportFlags(apiSixCmd.Flags())
 
 	var link2 = &cobra.Command{}
 	_ = copier.Copy(link2, linkCmd)
@@ -771,7 +859,13 @@ func init() {
 	_ = copier.Copy(load2, loaderCmd)
 	var clean2 = &cobra.Command{}
 	_ = copier.Copy(clean2, cleanCmd)
-	dashboardCmd.AddCommand(link2, load2, clean2)
+	var released2 = &cobra.Command{}
+	_ = copier.Copy(released2, releasedCmd)
+	bindReleasedFlags(released2.Flags())
+	dashboardCmd.AddCommand(link2, load2, clean2, released2)
+	bindSignFlags(dashboardCmd.Flags())
+	bindArchiveFlags(dashboardCmd.Flags())
+	bindReportFlags(dashboardCmd.Flags())
 
 	var link3 = &cobra.Command{}
 	_ = copier.Copy(link3, linkCmd)
@@ -780,7 +874,13 @@ func init() {
 	_ = copier.Copy(load3, loaderCmd)
 	var clean3 = &cobra.Command{}
 	_ = copier.Copy(clean3, cleanCmd)
-	goPluginRunnerCmd.AddCommand(link3, load3, clean3)
+	var released3 = &cobra.Command{}
+	_ = copier.Copy(released3, releasedCmd)
+	bindReleasedFlags(released3.Flags())
+	goPluginRunnerCmd.AddCommand(link3, load3, clean3, released3)
+	bindSignFlags(goPluginRunnerCmd.Flags())
+	bindArchiveFlags(goPluginRunnerCmd.Flags())
+	bindReportFlags(goPluginRunnerCmd.Flags())
 
 	var link4 = &cobra.Command{}
 	_ = copier.Copy(link4, linkCmd)
@@ -790,6 +890,12 @@ func init() {
 	_ = copier.Copy(load4, loaderCmd)
 	var clean4 = &cobra.Command{}
 	_ = copier.Copy(clean4, cleanCmd)
-	ingressControllerCmd.AddCommand(link4, load4, clean4)
+	var released4 = &cobra.Command{}
+	_ = copier.Copy(released4, releasedCmd)
+	bindReleasedFlags(released4.Flags())
+	ingressControllerCmd.AddCommand(link4, load4, clean4, released4)
 	bindExtraFlags(ingressControllerCmd.Flags())
+	bindSignFlags(ingressControllerCmd.Flags())
+	bindArchiveFlags(ingressControllerCmd.Flags())
+	bindReportFlags(ingressControllerCmd.Flags())
 }
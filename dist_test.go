@@ -15,90 +15,160 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 )
 
+const (
+	dashboardDevLink = "https://dist.apache.org/repos/dist/dev/apisix/apisix-dashboard-2.11.0"
+	dashboardCommit  = "2c563dc15c54a8deb3ba08707594d4d15da76b1b"
+)
+
+// dashboardRemote serve dist and GitHub of dashboard 2.11.0 candidate instead
+// of network, returns its source package; workspace opens in temporary directory
+func dashboardRemote(t *testing.T) []byte {
+	pkg := tgz(t, []string{"LICENSE", "NOTICE", "README.md"},
+		map[string]string{"LICENSE": "Apache License", "NOTICE": "Apache APISIX", "README.md": "# Apache APISIX Dashboard"})
+	name := "apache-apisix-dashboard-2.11.0-src.tgz"
+	dir := t.TempDir()
+	writeRelease(t, dir, name, pkg, newSigner(t, "Zeping Bai"))
+	asc, err := os.ReadFile(filepath.Join(dir, name+".asc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		dashboardDevLink:                          []byte("<html>" + name + "</html>"),
+		dashboardDevLink + "/" + name:             pkg,
+		dashboardDevLink + "/" + name + ".asc":    asc,
+		dashboardDevLink + "/" + name + ".sha512": []byte(fmt.Sprintf("%X  %s\n", sha512.Sum512(pkg), name)),
+		"https://github.com/apache/apisix-dashboard/blob/release/2.11/CHANGELOG.md": []byte("# Changelog"),
+		"https://github.com/apache/apisix-dashboard/commit/" + dashboardCommit:      []byte("commit"),
+	}
+
+	saved := transport
+	transport = fakeTransport(func(req *http.Request) (*http.Response, error) {
+		u := *req.URL
+		u.Fragment = ""
+		body, ok := files[u.String()]
+		if !ok {
+			return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(bytes.NewReader(body)), ContentLength: int64(len(body)), Request: req}, nil
+	})
+	workdir = t.TempDir()
+	if err := openWorkspace(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		transport, workdir, candidate, commitID = saved, "", "", ""
+		closeWorkspace()
+	})
+
+	return pkg
+}
+
 func TestDist_Validate(t *testing.T) {
-	candidate = "2.11.0"
+	dashboardRemote(t)
+	candidate, commitID = "2.11.0", dashboardCommit
 
-	tests := []struct {
-		name string
-		dist *Dist
-	}{
-		{
-			name: "test dashboard legal rc",
-			dist: NewDashboardDist(),
-		},
+	dist := NewDashboardDist()
+	if err := dist.ValidAllLinks(); err != nil {
+		t.Fatal(err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.dist.ValidAllLinks(); err != nil {
-				t.Error(err)
-			}
-		})
+	results := dist.Report().Results
+	if len(results) != 6 {
+		t.Fatalf("results = %+v, want 6", results)
+	}
+	for _, res := range results {
+		if !res.OK {
+			t.Errorf("%s bad %s", res.Item, res.Detail)
+		}
 	}
 }
 
 func TestDist_Validate2(t *testing.T) {
-	candidate = "2.11.1"
+	dashboardRemote(t)
+	candidate, commitID = "2.11.1", dashboardCommit
 
-	tests := []struct {
-		name string
-		dist *Dist
-	}{
-		{
-			name: "test dashboard illegal rc",
-			dist: NewDashboardDist(),
-		},
+	// candidate not under dist, which is reported rather than returned
+	dist := NewDashboardDist()
+	if err := dist.ValidAllLinks(); err != nil {
+		t.Fatal(err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Run(tt.name, func(t *testing.T) {
-				if err := tt.dist.ValidAllLinks(); err != nil {
-					t.Error(err)
-				}
-			})
-		})
+	bad := 0
+	for _, res := range dist.Report().Results {
+		if !res.OK {
+			bad++
+			if !strings.HasPrefix(res.Item, "dist ") {
+				t.Errorf("%s bad %s", res.Item, res.Detail)
+			}
+		}
+	}
+	if bad != 4 {
+		t.Errorf("bad dist links = %d, want 4", bad)
 	}
 }
 
 func TestDist_ValidChecksum(t *testing.T) {
+	dashboardRemote(t)
 	candidate = "2.11.0"
 
 	dist := NewDashboardDist()
+	if err := dist.fetchSrc(); err != nil {
+		t.Fatal(err)
+	}
+	if err := dist.fetchSrcSha512(); err != nil {
+		t.Fatal(err)
+	}
+
 	ok, err := dist.ValidChecksum()
-	if err != nil {
-		t.Error(err)
+	if err != nil || !ok {
+		t.Fatalf("ValidChecksum() = %v, %v", ok, err)
 	}
 
-	if !ok {
-		t.FailNow()
+	// checksum of another package
+	sum := fmt.Sprintf("%x  %s\n", sha512.Sum512([]byte("other")), dist.srcArchive())
+	if err := os.WriteFile(dist.path(dist.srcSha512()), []byte(sum), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := dist.ValidChecksum(); ok {
+		t.Error("ValidChecksum() of another package expect bad")
 	}
 }
 
 func TestDist_downloadSrc(t *testing.T) {
-	candidate = "2.11.0"
-	//timeout = 3
+	pkg := dashboardRemote(t)
 
 	tests := []struct {
-		name    string
-		dist    *Dist
-		wantErr bool
+		name      string
+		candidate string
+		wantErr   bool
 	}{
-		{
-			name:    "download dashboard src package",
-			dist:    NewDashboardDist(),
-			wantErr: false,
-		},
+		{name: "download dashboard src package", candidate: "2.11.0"},
+		{name: "candidate not under dist", candidate: "2.11.1", wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			//tt.dist.SetTimeout(10)
-			if err := tt.dist.fetchSrcTgz(); (err != nil) != tt.wantErr {
-				t.Errorf("fetchSrcTgz() error = %v, wantErr %v", err, tt.wantErr)
+			candidate = tt.candidate
+			dist := NewDashboardDist()
+			if err := dist.fetchSrc(); (err != nil) != tt.wantErr {
+				t.Fatalf("fetchSrc() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if body, err := os.ReadFile(dist.path(dist.srcArchive())); err != nil || !bytes.Equal(body, pkg) {
+				t.Errorf("downloaded src package differs, error = %v", err)
 			}
 		})
 	}
@@ -172,10 +242,19 @@ func TestDist_validKey(t *testing.T) {
 }
 
 func TestDist_checkExtras(t *testing.T) {
-	dist := NewDashboardDist()
-	dist.rc = "2.11.0"
+	dashboardRemote(t)
+	candidate = "2.11.0"
 
+	dist := NewDashboardDist()
+	if err := dist.fetchSrc(); err != nil {
+		t.Fatal(err)
+	}
 	if _, err := dist.CheckExtras(); err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+	for _, res := range dist.Report().Results {
+		if !res.OK {
+			t.Errorf("%s bad %s", res.Item, res.Detail)
+		}
 	}
 }
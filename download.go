@@ -0,0 +1,267 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	partSuffix = ".part"
+	metaSuffix = ".meta"
+)
+
+// A Meta represents validators of downloaded file
+type Meta struct {
+	Link         string `json:"link"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Size         int64  `json:"size"`
+}
+
+func newMeta(link string, res *http.Response) *Meta {
+	return &Meta{
+		Link:         link,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		Size:         -1,
+	}
+}
+
+func readMeta(filename string) (*Meta, error) {
+	body, err := os.ReadFile(filename + metaSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Meta
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (m *Meta) write(filename string) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename+metaSuffix, body, 0644)
+}
+
+// match whether response validators are the same as meta's
+func (m *Meta) match(res *http.Response) bool {
+	if m.ETag != "" && m.ETag != res.Header.Get("ETag") {
+		return false
+	}
+	if m.LastModified != "" && m.LastModified != res.Header.Get("Last-Modified") {
+		return false
+	}
+
+	return m.ETag != "" || m.LastModified != ""
+}
+
+// ifRange validator for conditional range request
+func (m *Meta) ifRange() string {
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		return m.ETag
+	}
+
+	return m.LastModified
+}
+
+// removeDownload remove downloaded file with its partial and meta files
+func removeDownload(filename string) error {
+	for _, name := range []string{filename, filename + partSuffix, filename + metaSuffix} {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cached whether filename is complete and up to date with link
+func (l *Linker) cached(link, filename string) (bool, error) {
+	f, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	meta, err := readMeta(filename)
+	if err != nil || meta.Link != link || meta.Size != f.Size() {
+		return false, nil
+	}
+
+	res, err := l.head(link)
+	if err != nil {
+		return false, err
+	}
+	if res.ContentLength >= 0 && res.ContentLength != f.Size() {
+		return false, nil
+	}
+
+	return meta.match(res), nil
+}
+
+// Download stream link into filename, the file appears only when completed,
+// partial download resumes by range request and cached file is validated before reuse
+func (l *Linker) Download(link, filename string) error {
+	if ok, err := l.cached(link, filename); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+
+	if l.cache != nil {
+		res, err := l.head(link)
+		if err != nil {
+			return err
+		}
+		if ok, err := l.cache.Restore(link, filename, res); err != nil {
+			return err
+		} else if ok {
+			return nil
+		}
+	}
+
+	if err := l.retrier.Do(l.context(), func() error {
+		return l.download(link, filename)
+	}); err != nil {
+		return err
+	}
+
+	if l.cache != nil {
+		if err := l.cache.Store(link, filename); err != nil {
+			log.Printf("cache %s bad ❌ %s\n", link, err)
+		}
+	}
+
+	return nil
+}
+
+// download one attempt, resume from partial file if any
+func (l *Linker) download(link, filename string) error {
+	part := filename + partSuffix
+	var offset int64
+	if f, err := os.Stat(part); err == nil {
+		offset = f.Size()
+	}
+	meta, err := readMeta(filename)
+	if err != nil || meta.Link != link || meta.ifRange() == "" {
+		offset = 0
+	}
+
+	req, err := l.request(http.MethodGet, link)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", meta.ifRange())
+	}
+
+	res, err := l.client().Do(req)
+	if err != nil {
+		return &UnreachableError{Link: link, Err: err}
+	}
+	defer res.Body.Close()
+
+	var total int64
+	flag := os.O_CREATE | os.O_WRONLY
+	switch res.StatusCode {
+	case http.StatusOK:
+		flag |= os.O_TRUNC
+		offset = 0
+		total = res.ContentLength
+	case http.StatusPartialContent:
+		flag |= os.O_APPEND
+		if total, err = contentRangeTotal(res.Header.Get("Content-Range"), offset); err != nil {
+			return err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// partial file is stale, start over next time
+		_ = removeDownload(filename)
+		return newStatusError(link, res)
+	default:
+		return newStatusError(link, res)
+	}
+
+	if res.StatusCode == http.StatusOK || meta == nil {
+		meta = newMeta(link, res)
+	}
+	if err := meta.write(filename); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(part, flag, 0644)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(f, res.Body)
+	if cerr := f.Close(); cerr != nil {
+		return cerr
+	}
+	if err != nil {
+		// keep partial file to resume
+		return &UnreachableError{Link: link, Err: err}
+	}
+
+	size := offset + n
+	if total >= 0 && size != total {
+		return &UnreachableError{Link: link, Err: fmt.Errorf("incomplete download %d of %d bytes", size, total)}
+	}
+	if size == 0 {
+		return fmt.Errorf("response body size zero")
+	}
+
+	meta.Size = size
+	if err := meta.write(filename); err != nil {
+		return err
+	}
+
+	return os.Rename(part, filename)
+}
+
+// contentRangeTotal parse complete length from Content-Range: bytes 100-199/200,
+// unknown length returns -1
+func contentRangeTotal(cr string, offset int64) (int64, error) {
+	var start, end int64
+	var total string
+	if _, err := fmt.Sscanf(cr, "bytes %d-%d/%s", &start, &end, &total); err != nil {
+		return 0, fmt.Errorf("invalid Content-Range %q", cr)
+	}
+	if start != offset {
+		return 0, fmt.Errorf("non-expected Content-Range %q from offset %d", cr, offset)
+	}
+
+	if total == "*" {
+		return -1, nil
+	}
+
+	return strconv.ParseInt(total, 10, 64)
+}
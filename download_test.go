@@ -0,0 +1,92 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinker_Download(t *testing.T) {
+	content := bytes.Repeat([]byte("apisix"), 1024)
+	modified := time.Date(2022, 3, 19, 0, 0, 0, 0, time.UTC)
+
+	var gets, ranges int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+			if r.Header.Get("Range") != "" {
+				ranges++
+			}
+		}
+		if r.URL.Path == "/truncated" {
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte("short"))
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "src.tgz", modified, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "src.tgz")
+	l := &Linker{timeout: 3}
+
+	if err := l.Download(srv.URL+"/src.tgz", filename); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filename); !bytes.Equal(got, content) {
+		t.Fatal("downloaded content mismatch")
+	}
+
+	// validated cache is reused without download
+	if err := l.Download(srv.URL+"/src.tgz", filename); err != nil {
+		t.Fatal(err)
+	}
+	if gets != 1 {
+		t.Errorf("cached file downloaded again, gets %d", gets)
+	}
+
+	// interrupted download resumes from partial file
+	_ = os.Rename(filename, filename+partSuffix)
+	_ = os.Truncate(filename+partSuffix, 1000)
+	if err := l.Download(srv.URL+"/src.tgz", filename); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filename); !bytes.Equal(got, content) {
+		t.Fatal("resumed content mismatch")
+	}
+	if ranges != 1 {
+		t.Errorf("partial file not resumed, ranges %d", ranges)
+	}
+
+	// truncated body never becomes the final file
+	truncated := filepath.Join(dir, "truncated")
+	if err := l.Download(srv.URL+"/truncated", truncated); err == nil {
+		t.Error("truncated download expect error")
+	} else if !strings.Contains(err.Error(), "EOF") && !strings.Contains(err.Error(), "incomplete") {
+		t.Error(err)
+	}
+	if _, err := os.Stat(truncated); !os.IsNotExist(err) {
+		t.Error("truncated download should not exist")
+	}
+}
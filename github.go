@@ -16,8 +16,8 @@ package main
 
 import (
 	"fmt"
-	"log"
 	"strings"
+	"unicode"
 )
 
 const (
@@ -31,18 +31,42 @@ type Git struct {
 	Release string
 	Blob    string // only work for release-note
 	Tag     string
+	Branch  string // release branch, like release/2.11
+	Anchor  string // CHANGELOG heading, defaults to Release
 }
 
-// MarkdownID fetch markdown from release, 2.11.0 -> 2110
+// MarkdownID fetch markdown anchor from release heading, 2.11.0 -> 2110
 func (g *Git) MarkdownID() string {
-	return strings.ReplaceAll(g.Release, ".", "")
+	heading := g.Anchor
+	if heading == "" {
+		heading = g.Release
+	}
+
+	return markdownAnchor(heading)
+}
+
+// markdownAnchor generate anchor as github does: lower case,
+// drop punctuation except hyphen and underscore, spaces into hyphens
+func markdownAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+
+	return b.String()
 }
 
 // GitHub validator for github link
 type GitHub struct {
 	Linker
 
-	git *Git
+	git    *Git
+	report *Report
 }
 
 // NewGitHub GitHub instance
@@ -52,10 +76,8 @@ func NewGitHub(g *Git) (*GitHub, error) {
 	}
 
 	return &GitHub{
-		Linker: Linker{
-			timeout: timeout,
-		},
-		git: g,
+		Linker: newLinker(),
+		git:    g,
 	}, nil
 }
 
@@ -63,26 +85,22 @@ func (g *GitHub) releaseNoteLink() string {
 	if g.git.Blob != "" {
 		return fmt.Sprintf("%s/%s/blob/%s/CHANGELOG.md#%s", githubApacheOgz, g.git.Repo, g.git.Blob, g.git.MarkdownID())
 	}
-	return fmt.Sprintf("%s/%s/blob/release/%s/CHANGELOG.md#%s", githubApacheOgz, g.git.Repo, g.git.Tag, g.git.MarkdownID())
+	return fmt.Sprintf("%s/%s/blob/%s/CHANGELOG.md#%s", githubApacheOgz, g.git.Repo, g.git.Branch, g.git.MarkdownID())
 }
 
 func (g *GitHub) releaseCommitLink() string {
 	return fmt.Sprintf("%s/%s/commit/%s", githubApacheOgz, g.git.Repo, g.git.Commit)
 }
 
-// ValidLinks validate release links
-func (g *GitHub) ValidLinks() error {
-	links := []string{g.releaseNoteLink(), g.releaseCommitLink()}
-	for _, link := range links {
-		if ok, err := g.Linker.Head(link); err != nil {
-			log.Printf("github %s validate bad ❌ %s\n", link, err)
-			return err
-		} else if ok {
-			log.Printf("github %s validate ok ✅\n", link)
-		} else {
-			log.Printf("github %s validate bad ❌\n", link)
-		}
+// checks github links to validate
+func (g *GitHub) checks() []linkCheck {
+	return []linkCheck{
+		{kind: "github", link: g.releaseNoteLink()},
+		{kind: "github", link: g.releaseCommitLink()},
 	}
+}
 
-	return nil
+// ValidLinks validate release links
+func (g *GitHub) ValidLinks() error {
+	return g.Linker.HeadAll(g.checks(), g.report)
 }
@@ -17,6 +17,8 @@ package main
 import "testing"
 
 func TestGitHub_ValidLinks(t *testing.T) {
+	dashboardRemote(t)
+
 	type fields struct {
 		Linker Linker
 		git    *Git
@@ -25,17 +27,20 @@ func TestGitHub_ValidLinks(t *testing.T) {
 		name    string
 		fields  fields
 		wantErr bool
+		bad     int
 	}{
 		{
 			name: "valid links",
 			fields: fields{
 				Linker: Linker{
-					timeout: 3,
+					timeout:   3,
+					transport: transport,
 				},
 				git: &Git{
 					Commit:  "2c563dc15c54a8deb3ba08707594d4d15da76b1b",
 					Repo:    pkgAPISixDashboard,
 					Release: "2.11.0",
+					Branch:  "release/2.11",
 				}},
 			wantErr: false,
 		},
@@ -45,10 +50,20 @@ func TestGitHub_ValidLinks(t *testing.T) {
 			g := &GitHub{
 				Linker: tt.fields.Linker,
 				git:    tt.fields.git,
+				report: &Report{},
 			}
 			if err := g.ValidLinks(); (err != nil) != tt.wantErr {
 				t.Errorf("ValidLinks() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			bad := 0
+			for _, res := range g.report.Results {
+				if !res.OK {
+					bad++
+				}
+			}
+			if bad != tt.bad {
+				t.Errorf("bad links = %d, want %d: %+v", bad, tt.bad, g.report.Results)
+			}
 		})
 	}
 }
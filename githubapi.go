@@ -0,0 +1,294 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	githubAPILink = "https://api.github.com"
+	githubOwner   = "apache"
+)
+
+// githubAPI base URL of GitHub REST API from flag, empty disables API checks
+var githubAPI string
+
+// A GitHubAPI requests GitHub REST API of a repository under apache,
+// authorized by GITHUB_TOKEN if any
+type GitHubAPI struct {
+	Linker
+
+	base  string
+	token string
+	repo  string
+}
+
+// NewGitHubAPI API of repo, base like https://api.github.com or a local stand-in
+func NewGitHubAPI(base, repo string) *GitHubAPI {
+	return &GitHubAPI{
+		Linker: newLinker(),
+		base:   strings.TrimSuffix(base, "/"),
+		token:  os.Getenv("GITHUB_TOKEN"),
+		repo:   repo,
+	}
+}
+
+// link API URL of path under the repository
+func (a *GitHubAPI) link(path string) string {
+	return fmt.Sprintf("%s/repos/%s/%s%s", a.base, githubOwner, a.repo, path)
+}
+
+// get decode JSON response of path
+func (a *GitHubAPI) get(path string, v interface{}) error {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	if a.token != "" {
+		header.Set("Authorization", "Bearer "+a.token)
+	}
+
+	body, err := a.Linker.GetWithHeader(a.link(path), header)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(body), v)
+}
+
+// Commit full SHA of commit ref
+func (a *GitHubAPI) Commit(ref string) (string, error) {
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	if err := a.get("/commits/"+escapeRef(ref), &commit); err != nil {
+		return "", err
+	}
+
+	return commit.SHA, nil
+}
+
+// Branch SHA of branch head
+func (a *GitHubAPI) Branch(name string) (string, error) {
+	var branch struct {
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	if err := a.get("/branches/"+escapeRef(name), &branch); err != nil {
+		return "", err
+	}
+
+	return branch.Commit.SHA, nil
+}
+
+// Compare status of head against base: ahead, behind, diverged or identical
+func (a *GitHubAPI) Compare(base, head string) (string, error) {
+	var cmp struct {
+		Status string `json:"status"`
+	}
+	if err := a.get(fmt.Sprintf("/compare/%s...%s", escapeRef(base), escapeRef(head)), &cmp); err != nil {
+		return "", err
+	}
+
+	return cmp.Status, nil
+}
+
+// gitObject object which a ref or an annotated tag points at
+type gitObject struct {
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+}
+
+// TagCommit SHA of commit which tag points at, annotated tags peeled
+func (a *GitHubAPI) TagCommit(tag string) (string, error) {
+	var ref struct {
+		Object gitObject `json:"object"`
+	}
+	if err := a.get("/git/ref/tags/"+escapeRef(tag), &ref); err != nil {
+		return "", err
+	}
+
+	obj := ref.Object
+	for i := 0; obj.Type == "tag"; i++ {
+		if i >= 8 {
+			return "", fmt.Errorf("tag %s nested too deep", tag)
+		}
+
+		var annotated struct {
+			Object gitObject `json:"object"`
+		}
+		if err := a.get("/git/tags/"+obj.SHA, &annotated); err != nil {
+			return "", err
+		}
+		obj = annotated.Object
+	}
+
+	if obj.Type != "commit" {
+		return "", fmt.Errorf("tag %s points at %s %s, not a commit", tag, obj.Type, obj.SHA)
+	}
+
+	return obj.SHA, nil
+}
+
+// Content file content at ref
+func (a *GitHubAPI) Content(path, ref string) ([]byte, error) {
+	var content struct {
+		Encoding string `json:"encoding"`
+		Content  string `json:"content"`
+	}
+	if err := a.get(fmt.Sprintf("/contents/%s?ref=%s", path, url.QueryEscape(ref)), &content); err != nil {
+		return nil, err
+	}
+
+	if content.Encoding != "base64" {
+		return nil, fmt.Errorf("%s encoding %s unsupported", path, content.Encoding)
+	}
+
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+}
+
+// escapeRef escape each segment of ref, slashes kept as GitHub expects
+func escapeRef(ref string) string {
+	segments := strings.Split(ref, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// sameCommit whether full SHA matches commit, which may be abbreviated
+func sameCommit(full, commit string) bool {
+	return len(commit) >= 7 && strings.HasPrefix(strings.ToLower(full), strings.ToLower(commit))
+}
+
+// changelogSection text under heading whose anchor is anchor, until next
+// heading of the same or upper level
+func changelogSection(body []byte, anchor string) (string, bool) {
+	var section strings.Builder
+	level := 0
+	s := bufio.NewScanner(strings.NewReader(string(body)))
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, "#") {
+			n := len(line) - len(strings.TrimLeft(line, "#"))
+			if level > 0 && n <= level {
+				break
+			}
+			if level == 0 && markdownAnchor(strings.TrimSpace(line[n:])) == anchor {
+				level = n
+				continue
+			}
+		}
+		if level > 0 {
+			section.WriteString(line)
+			section.WriteByte('\n')
+		}
+	}
+
+	return section.String(), level > 0
+}
+
+// changelogRef ref which CHANGELOG read from, release-note branch if any
+func (g *GitHub) changelogRef() string {
+	if g.git.Blob != "" {
+		return g.git.Blob
+	}
+
+	return g.git.Branch
+}
+
+// apiChecks commit, release branch, tag and CHANGELOG section via API
+func (g *GitHub) apiChecks(api *GitHubAPI) []itemCheck {
+	git := g.git
+	ref := g.changelogRef()
+
+	var checks []itemCheck
+	if git.Commit != "" {
+		checks = append(checks,
+			itemCheck{
+				item: fmt.Sprintf("github api commit %s exists", git.Commit),
+				fn: func() (bool, error) {
+					sha, err := api.Commit(git.Commit)
+					return err == nil && sameCommit(sha, git.Commit), err
+				},
+			},
+			itemCheck{
+				item: fmt.Sprintf("github api branch %s contains commit", git.Branch),
+				fn: func() (bool, error) {
+					if _, err := api.Branch(git.Branch); err != nil {
+						return false, err
+					}
+					status, err := api.Compare(git.Commit, git.Branch)
+					if err != nil {
+						return false, err
+					}
+					if status != "ahead" && status != "identical" {
+						return false, fmt.Errorf("branch %s %s commit", git.Branch, status)
+					}
+					return true, nil
+				},
+			},
+			itemCheck{
+				item: fmt.Sprintf("github api tag %s points at commit", git.Tag),
+				fn: func() (bool, error) {
+					sha, err := api.TagCommit(git.Tag)
+					if err != nil {
+						return false, err
+					}
+					if !sameCommit(sha, git.Commit) {
+						return false, fmt.Errorf("tag %s points at %s", git.Tag, sha)
+					}
+					return true, nil
+				},
+			},
+		)
+	} else {
+		checks = append(checks, itemCheck{
+			item: fmt.Sprintf("github api branch %s exists", git.Branch),
+			fn: func() (bool, error) {
+				_, err := api.Branch(git.Branch)
+				return err == nil, err
+			},
+		})
+	}
+
+	checks = append(checks, itemCheck{
+		item: fmt.Sprintf("github api CHANGELOG on %s has section %s", ref, git.MarkdownID()),
+		fn: func() (bool, error) {
+			body, err := api.Content("CHANGELOG.md", ref)
+			if err != nil {
+				return false, err
+			}
+			_, ok := changelogSection(body, git.MarkdownID())
+			return ok, nil
+		},
+	})
+
+	return checks
+}
+
+// ValidAPI verify commit, release branch, tag and CHANGELOG via API
+func (g *GitHub) ValidAPI(api *GitHubAPI) error {
+	return runItemChecks(api.workers, g.apiChecks(api), g.report)
+}
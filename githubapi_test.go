@@ -0,0 +1,131 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	releaseCommit = "3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e"
+	tagObject     = "aa11bb22cc33dd44ee55ff6677889900aabbccdd"
+)
+
+const changelog = `# Table of Contents
+
+## 2.11.0
+
+### Core
+
+- feat: plugin list (#2301)
+- fix: route page (#2310)
+
+## 2.10.1
+
+- fix: login (#2200)
+`
+
+// fakeGitHub serves GitHub API responses by request URI, requires token if any
+func fakeGitHub(t *testing.T, token string, responses map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		res, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+}
+
+// dashboardAPI responses of apisix-dashboard release 2.11.0
+func dashboardAPI(status string) map[string]interface{} {
+	repo := "/repos/apache/apisix-dashboard"
+	return map[string]interface{}{
+		repo + "/commits/3f2e1d0":                          map[string]string{"sha": releaseCommit},
+		repo + "/branches/release/2.11":                    map[string]interface{}{"commit": map[string]string{"sha": releaseCommit}},
+		repo + "/compare/3f2e1d0...release/2.11":           map[string]string{"status": status},
+		repo + "/git/ref/tags/v2.11.0":                     map[string]interface{}{"object": map[string]string{"type": "tag", "sha": tagObject}},
+		repo + "/git/tags/" + tagObject:                    map[string]interface{}{"object": map[string]string{"type": "commit", "sha": releaseCommit}},
+		repo + "/contents/CHANGELOG.md?ref=release%2F2.11": map[string]string{"encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(changelog))},
+	}
+}
+
+func TestGitHub_ValidAPI(t *testing.T) {
+	git := &Git{
+		Repo:    pkgAPISixDashboard,
+		Commit:  "3f2e1d0",
+		Release: "2.11.0",
+		Tag:     "v2.11.0",
+		Branch:  "release/2.11",
+	}
+
+	tests := []struct {
+		name   string
+		status string
+		token  string
+		bad    string
+	}{
+		{name: "release", status: "ahead"},
+		{name: "authorized", status: "identical", token: "ghp_secret"},
+		{name: "commit not on branch", status: "diverged", bad: "github api branch release/2.11 contains commit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeGitHub(t, tt.token, dashboardAPI(tt.status))
+			defer srv.Close()
+			t.Setenv("GITHUB_TOKEN", tt.token)
+
+			g := &GitHub{git: git, report: &Report{}}
+			api := NewGitHubAPI(srv.URL+"/", git.Repo)
+			api.Linker = Linker{timeout: 3, workers: 4}
+			if err := g.ValidAPI(api); err != nil {
+				t.Fatalf("ValidAPI() error = %v", err)
+			}
+
+			if len(g.report.Results) != 4 {
+				t.Fatalf("results = %+v, want 4", g.report.Results)
+			}
+			for _, res := range g.report.Results {
+				if res.OK == (res.Item == tt.bad) {
+					t.Errorf("%s = %v %s", res.Item, res.OK, res.Detail)
+				}
+			}
+		})
+	}
+}
+
+func TestChangelogSection(t *testing.T) {
+	section, ok := changelogSection([]byte(changelog), "2110")
+	if !ok || !strings.Contains(section, "#2310") || strings.Contains(section, "#2200") {
+		t.Errorf("changelogSection(2110) = %q, %v", section, ok)
+	}
+
+	if _, ok := changelogSection([]byte(changelog), "2120"); ok {
+		t.Error("changelogSection(2120) expect not found")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+)
+
+const (
+	checkGitHubArchive = "github-archive"
+	gitattributesName  = ".gitattributes"
+)
+
+var (
+	compareGitHub     bool
+	githubArchiveFile string
+	generatedFiles    []string
+)
+
+// withGenerated generated file patterns of project definition extended by flag
+func withGenerated(patterns ...string) []string {
+	return append(patterns, generatedFiles...)
+}
+
+// GitHubArchiveLink archive GitHub generates for tag of repo
+func GitHubArchiveLink(repo, tag string) string {
+	return fmt.Sprintf("%s/%s/archive/refs/tags/%s.tar.gz", githubApacheOgz, repo, escapeRef(tag))
+}
+
+// githubArchiveCheck compares files of src archive with the archive GitHub
+// generates for release tag, what release manager added or removed when
+// packaging shows up; export-ignore and generated files are skipped
+type githubArchiveCheck struct {
+	dist *Dist
+	tree *fileTree
+}
+
+func (c *githubArchiveCheck) Begin(archive string) error {
+	return nil
+}
+
+func (c *githubArchiveCheck) Entry(hdr *tar.Header, r io.Reader) error {
+	return c.tree.visit(hdr, r)
+}
+
+func (c *githubArchiveCheck) End() []Result {
+	d := c.dist
+	item := fmt.Sprintf("github archive of tag %s", d.Tag())
+	filename, err := d.githubArchive()
+	if err != nil {
+		return []Result{{Item: item, Kind: errorKind(err), Detail: err.Error()}}
+	}
+
+	github, err := readTree(filename, func(name string) bool {
+		return path.Base(name) == gitattributesName
+	})
+	if err != nil {
+		return []Result{{Item: item, Detail: err.Error()}}
+	}
+
+	ignored := append([]string{}, d.generated...)
+	if f, ok := github.files[gitattributesName]; ok {
+		ignored = append(ignored, exportIgnored(f.body)...)
+	}
+
+	c.tree.stripTop()
+	diff := diffTrees(github, c.tree, func(name string) bool {
+		return matchAny(ignored, name)
+	})
+
+	return []Result{
+		{Item: item, OK: true},
+		listed("github archive files added in src archive", diff.Added),
+		listed("github archive files removed from src archive", diff.Removed),
+		listed("github archive files modified in src archive", diff.Modified),
+	}
+}
+
+// githubArchive local GitHub archive of tag, downloaded unless specified
+func (d *Dist) githubArchive() (string, error) {
+	if d.githubArchiveFile != "" {
+		return d.githubArchiveFile, nil
+	}
+
+	name := fmt.Sprintf("github-%s-%s.tar.gz", d.repo, filepath.Base(d.Tag()))
+	if err := d.download(GitHubArchiveLink(d.repo, d.Tag()), name); err != nil {
+		return "", err
+	}
+
+	return d.path(name), nil
+}
+
+func init() {
+	RegisterArchiveCheck(checkGitHubArchive, func(d *Dist) ArchiveCheck {
+		return &githubArchiveCheck{dist: d, tree: newFileTree(nil)}
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGitHubArchiveCheck(t *testing.T) {
+	generatedFiles = []string{"/api/conf/schema.json"}
+	defer func() { generatedFiles = nil }()
+
+	// web/dist generated by dashboard definition, extended by flag
+	generated := NewDashboardDist().generated
+	if want := []string{"/web/dist", "/api/conf/schema.json"}; !reflect.DeepEqual(generated, want) {
+		t.Errorf("generated = %v, want %v", generated, want)
+	}
+
+	d := &Dist{
+		Candidate: Candidate{
+			pkg:       pkgAPISixDashboard,
+			rc:        Semver{Major: 2, Minor: 11},
+			pkgPrefix: prefixApache,
+			naming:    dashboardNaming,
+		},
+		repo:      pkgAPISixDashboard,
+		dir:       t.TempDir(),
+		checks:    []string{checkGitHubArchive},
+		generated: generated,
+		report:    &Report{},
+	}
+
+	// GitHub archive has top directory, dist tarball of dashboard not
+	top := "apisix-dashboard-2.11.0/"
+	github := map[string]string{
+		top + ".gitattributes":            "/.github export-ignore\n*.snap  export-ignore\n# docs export-ignore\n",
+		top + ".github/workflows/ci.yml":  "on: push",
+		top + ".asf.yaml":                 "github:",
+		top + "LICENSE":                   "Apache License",
+		top + "Makefile":                  "build:",
+		top + "web/src/a.test.snap":       "snapshot",
+		top + "api/internal/conf/conf.go": "package conf",
+	}
+	var names []string
+	for name := range github {
+		names = append(names, name)
+	}
+	d.githubArchiveFile = filepath.Join(t.TempDir(), "v2.11.0.tar.gz")
+	if err := os.WriteFile(d.githubArchiveFile, tgz(t, names, github), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := map[string]string{
+		"LICENSE":                   "Apache License",
+		"Makefile":                  "build: web",
+		"api/internal/conf/conf.go": "package conf",
+		"web/dist/index.html":       "<html>",
+		"build.sh":                  "#!/bin/sh",
+	}
+	names = nil
+	for name := range src {
+		names = append(names, name)
+	}
+	if err := os.WriteFile(d.path(d.srcArchive()), tgz(t, names, src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.CheckExtras(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Result{
+		{Item: "github archive of tag v2.11.0", OK: true},
+		{Item: "github archive files added in src archive", Detail: "build.sh"},
+		{Item: "github archive files removed from src archive", Detail: ".asf.yaml, .gitattributes"},
+		{Item: "github archive files modified in src archive", Detail: "Makefile"},
+	}
+	results := d.Report().Results
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v, want %d", results, len(want))
+	}
+	for i, res := range results {
+		if res != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, res, want[i])
+		}
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.snap", name: "web/src/a.test.snap", want: true},
+		{pattern: "/.github", name: ".github/workflows/ci.yml", want: true},
+		{pattern: "/.github", name: "docs/.github", want: false},
+		{pattern: "e2e", name: "web/e2e/login.js", want: true},
+		{pattern: "web/e2e/", name: "web/e2e/login.js", want: true},
+		{pattern: "web/e2e", name: "api/web/e2e/login.js", want: false},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
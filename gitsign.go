@@ -0,0 +1,346 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+const (
+	keysFilename = "KEYS"
+
+	pgpSignatureBegin = "-----BEGIN PGP SIGNATURE-----"
+)
+
+// A signedObject represents git tag or commit with its OpenPGP signature split off
+type signedObject struct {
+	payload   []byte // object content which is signed
+	signature []byte // armored signature, empty if unsigned
+	signer    string // tagger or committer name
+	email     string // tagger or committer email
+}
+
+// A gitObjects provides raw tag and commit objects of release
+type gitObjects interface {
+	Tag(name string) (*signedObject, error)
+	Commit(ref string) (*signedObject, error)
+}
+
+// identity name and email of git identity line, like "tagger Name <email> 1647648000 +0800"
+func identity(line string) (name, email string) {
+	if i := strings.Index(line, " <"); i >= 0 {
+		email = line[i+2:]
+		if j := strings.Index(email, ">"); j >= 0 {
+			email = email[:j]
+		}
+		line = line[:i]
+	}
+	if i := strings.Index(line, " "); i >= 0 {
+		name = line[i+1:]
+	}
+
+	return name, email
+}
+
+// parseTagObject split signature appended to annotated tag object
+func parseTagObject(raw []byte) *signedObject {
+	obj := &signedObject{payload: raw}
+	if i := bytes.Index(raw, []byte(pgpSignatureBegin)); i >= 0 {
+		obj.payload, obj.signature = raw[:i], raw[i:]
+	}
+
+	for _, line := range strings.Split(string(obj.payload), "\n") {
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "tagger ") {
+			obj.signer, obj.email = identity(line)
+		}
+	}
+
+	return obj
+}
+
+// parseCommitObject split gpgsig header off commit object, continuation
+// lines of the header start with a space
+func parseCommitObject(raw []byte) *signedObject {
+	obj := &signedObject{}
+	var payload, signature bytes.Buffer
+
+	lines := strings.SplitAfter(string(raw), "\n")
+	inHeader, inSig := true, false
+	for _, line := range lines {
+		switch {
+		case !inHeader:
+			payload.WriteString(line)
+		case inSig && strings.HasPrefix(line, " "):
+			signature.WriteString(line[1:])
+		case strings.HasPrefix(line, "gpgsig "):
+			inSig = true
+			signature.WriteString(strings.TrimPrefix(line, "gpgsig "))
+		default:
+			inSig = false
+			if line == "\n" {
+				inHeader = false
+			} else if strings.HasPrefix(line, "committer ") {
+				obj.signer, obj.email = identity(strings.TrimSuffix(line, "\n"))
+			}
+			payload.WriteString(line)
+		}
+	}
+
+	obj.payload, obj.signature = payload.Bytes(), signature.Bytes()
+	return obj
+}
+
+// A localGit reads objects from a local clone
+type localGit struct {
+	dir string
+}
+
+func (g *localGit) catFile(kind, ref string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", g.dir, "cat-file", "-t", ref)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file %s: %s", ref, strings.TrimSpace(stderr.String()))
+	}
+	if got := strings.TrimSpace(string(out)); got != kind {
+		return nil, fmt.Errorf("%s is a %s, not an annotated %s", ref, got, kind)
+	}
+
+	stderr.Reset()
+	cmd = exec.Command("git", "-C", g.dir, "cat-file", kind, ref)
+	cmd.Stderr = &stderr
+	if out, err = cmd.Output(); err != nil {
+		return nil, fmt.Errorf("git cat-file %s: %s", ref, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+func (g *localGit) Tag(name string) (*signedObject, error) {
+	raw, err := g.catFile("tag", "refs/tags/"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTagObject(raw), nil
+}
+
+func (g *localGit) Commit(ref string) (*signedObject, error) {
+	raw, err := g.catFile("commit", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCommitObject(raw), nil
+}
+
+// An apiGit reads objects through GitHub API, which returns signed payload
+// and signature of verification
+type apiGit struct {
+	api *GitHubAPI
+}
+
+// verification of tag or commit in GitHub API
+type verification struct {
+	Signature string `json:"signature"`
+	Payload   string `json:"payload"`
+}
+
+func (g *apiGit) Tag(name string) (*signedObject, error) {
+	var ref struct {
+		Object gitObject `json:"object"`
+	}
+	if err := g.api.get("/git/ref/tags/"+escapeRef(name), &ref); err != nil {
+		return nil, err
+	}
+	if ref.Object.Type != "tag" {
+		return nil, fmt.Errorf("tag %s is lightweight, not an annotated tag", name)
+	}
+
+	var tag struct {
+		Tagger struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"tagger"`
+		Verification verification `json:"verification"`
+	}
+	if err := g.api.get("/git/tags/"+ref.Object.SHA, &tag); err != nil {
+		return nil, err
+	}
+
+	return &signedObject{
+		payload:   []byte(tag.Verification.Payload),
+		signature: []byte(tag.Verification.Signature),
+		signer:    tag.Tagger.Name,
+		email:     tag.Tagger.Email,
+	}, nil
+}
+
+func (g *apiGit) Commit(ref string) (*signedObject, error) {
+	var commit struct {
+		Commit struct {
+			Committer struct {
+				Name  string `json:"name"`
+				Email string `json:"email"`
+			} `json:"committer"`
+			Verification verification `json:"verification"`
+		} `json:"commit"`
+	}
+	if err := g.api.get("/commits/"+escapeRef(ref), &commit); err != nil {
+		return nil, err
+	}
+
+	return &signedObject{
+		payload:   []byte(commit.Commit.Verification.Payload),
+		signature: []byte(commit.Commit.Verification.Signature),
+		signer:    commit.Commit.Committer.Name,
+		email:     commit.Commit.Committer.Email,
+	}, nil
+}
+
+// verifySigned verify object signature against keyring, signer key must
+// belong to announcer if specified
+func verifySigned(keyring openpgp.KeyRing, obj *signedObject, announcer string) (bool, error) {
+	if len(obj.signature) == 0 {
+		return false, fmt.Errorf("not signed")
+	}
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(obj.payload), bytes.NewReader(obj.signature), nil)
+	if err != nil {
+		return false, err
+	}
+
+	if announcer != "" && !signedBy(signer, announcer) {
+		return false, fmt.Errorf("signed by %s, not announcer %s", signer.PrimaryKey.KeyIdString(), announcer)
+	}
+
+	return true, nil
+}
+
+// gitObjects source of release objects: local clone, then GitHub API, nil if neither
+func (d *Dist) gitObjects() gitObjects {
+	switch {
+	case d.gitDir != "":
+		return &localGit{dir: d.gitDir}
+	case d.api != "":
+		return &apiGit{api: d.githubAPI()}
+	}
+
+	return nil
+}
+
+// projectKeyRing KEYS of project, downloaded from dist unless specified
+func (d *Dist) projectKeyRing() (openpgp.EntityList, error) {
+	if d.keys != "" {
+		return readKeyRing(d.keys)
+	}
+
+	if err := d.download(d.KeysLink(), keysFilename); err != nil {
+		return nil, err
+	}
+
+	return readKeyRing(d.path(keysFilename))
+}
+
+// isAnnouncer whether tagger name is announcer regardless of case, and its
+// email belongs to announcer's key in keyring if both known
+func isAnnouncer(keyring openpgp.EntityList, obj *signedObject, announcer string) (bool, error) {
+	if announcer == "" {
+		return true, nil
+	}
+	if !isAnnouncerName(obj.signer, announcer) {
+		return false, fmt.Errorf("tagged by %s", obj.signer)
+	}
+	if obj.email == "" {
+		return true, nil
+	}
+
+	var emails []string
+	for _, e := range keyring {
+		for _, id := range e.Identities {
+			if isAnnouncerName(id.UserId.Name, announcer) {
+				emails = append(emails, id.UserId.Email)
+			}
+		}
+	}
+	for _, email := range emails {
+		if strings.EqualFold(email, obj.email) {
+			return true, nil
+		}
+	}
+	if len(emails) == 0 {
+		return true, nil
+	}
+
+	return false, fmt.Errorf("tagged by %s <%s>, not email of announcer key %s", obj.signer, obj.email, strings.Join(emails, ", "))
+}
+
+// VerifyGit verify signatures of release tag and commit against project KEYS
+// if enabled, tagger must be the announcer, unsigned tag and commit skipped
+func (d *Dist) VerifyGit() {
+	if !d.verifyGit {
+		return
+	}
+	objects := d.gitObjects()
+	if objects == nil {
+		return
+	}
+
+	keyring, err := d.projectKeyRing()
+	if err != nil {
+		d.report.Record("git project KEYS", false, err)
+		return
+	}
+
+	tag := d.Tag()
+	if obj, err := objects.Tag(tag); err != nil {
+		d.report.Record(fmt.Sprintf("git tag %s signature", tag), false, err)
+	} else {
+		if len(obj.signature) == 0 {
+			log.Printf("git tag %s not signed, skip\n", tag)
+		} else {
+			ok, err := verifySigned(keyring, obj, d.announcer)
+			d.report.Record(fmt.Sprintf("git tag %s signature", tag), ok, err)
+		}
+
+		ok, err := isAnnouncer(keyring, obj, d.announcer)
+		d.report.Record(fmt.Sprintf("git tag %s tagger is announcer", tag), ok, err)
+	}
+
+	if d.commit == "" {
+		return
+	}
+
+	obj, err := objects.Commit(d.commit)
+	switch {
+	case err != nil:
+		d.report.Record(fmt.Sprintf("git commit %s signature", d.commit), false, err)
+	case len(obj.signature) == 0:
+		log.Printf("git commit %s not signed, skip\n", d.commit)
+	default:
+		ok, err := verifySigned(keyring, obj, d.announcer)
+		d.report.Record(fmt.Sprintf("git commit %s signature", d.commit), ok, err)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// armoredSign detached armored signature of payload
+func armoredSign(t *testing.T, signer *openpgp.Entity, payload string) string {
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, signer, strings.NewReader(payload), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return sig.String() + "\n"
+}
+
+// gitDist dashboard dist of release 2.11.0 reading git objects
+func gitDist(t *testing.T, keys string) *Dist {
+	d := NewDashboardDist()
+	d.rc = Semver{Major: 2, Minor: 11}
+	d.announcer = "Zeping Bai"
+	d.keys = keys
+	d.dir = t.TempDir()
+	d.verifyGit = true
+	return d
+}
+
+// results items of report, ok or not
+func results(r *Report) map[string]bool {
+	got := map[string]bool{}
+	for _, res := range r.Results {
+		got[res.Item] = res.OK
+	}
+
+	return got
+}
+
+func TestDist_VerifyGitAPI(t *testing.T) {
+	manager, other := newSigner(t, "Zeping Bai"), newSigner(t, "Someone Else")
+	keys := writeKeys(t, manager, other)
+
+	tagPayload := "object " + releaseCommit + "\ntype commit\ntag v2.11.0\ntagger Zeping Bai <release@apache.org> 1647648000 +0800\n\nrelease 2.11.0\n"
+	commitPayload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor Someone Else <a@apache.org> 1647648000 +0800\ncommitter Someone Else <a@apache.org> 1647648000 +0800\n\nrelease\n"
+
+	repo := "/repos/apache/apisix-dashboard"
+	srv := fakeGitHub(t, "", map[string]interface{}{
+		repo + "/git/ref/tags/v2.11.0": map[string]interface{}{"object": map[string]string{"type": "tag", "sha": tagObject}},
+		repo + "/git/tags/" + tagObject: map[string]interface{}{
+			"tagger":       map[string]string{"name": "zeping bai", "email": "release@apache.org"},
+			"verification": map[string]string{"payload": tagPayload, "signature": armoredSign(t, manager, tagPayload)},
+		},
+		repo + "/commits/3f2e1d0": map[string]interface{}{"commit": map[string]interface{}{
+			"committer":    map[string]string{"name": "Someone Else"},
+			"verification": map[string]string{"payload": commitPayload, "signature": armoredSign(t, other, commitPayload)},
+		}},
+	})
+	defer srv.Close()
+
+	d := gitDist(t, keys)
+	d.api = srv.URL
+	d.commit = "3f2e1d0"
+	d.VerifyGit()
+
+	want := map[string]bool{
+		"git tag v2.11.0 signature":           true,
+		"git tag v2.11.0 tagger is announcer": true,
+		"git commit 3f2e1d0 signature":        false, // signed by other than announcer
+	}
+	if got := results(d.Report()); len(got) != len(want) {
+		t.Errorf("results = %v, want %v", got, want)
+	} else {
+		for item, ok := range want {
+			if got[item] != ok {
+				t.Errorf("%s = %v, want %v", item, got[item], ok)
+			}
+		}
+	}
+}
+
+// git run git in dir, returns trimmed output
+func git(t *testing.T, dir, stdin string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Zeping Bai", "GIT_AUTHOR_EMAIL=release@apache.org",
+		"GIT_COMMITTER_NAME=Zeping Bai", "GIT_COMMITTER_EMAIL=release@apache.org",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %s %s", args, err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+func TestDist_VerifyGitLocal(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	manager := newSigner(t, "Zeping Bai")
+	dir := t.TempDir()
+	git(t, dir, "", "init", "-q")
+	git(t, dir, "", "commit", "-q", "--allow-empty", "-m", "release 2.11.0")
+
+	// commit signed by release manager, gpgsig header continued by spaces
+	raw := git(t, dir, "", "cat-file", "commit", "HEAD") + "\n"
+	sig := strings.TrimSuffix(armoredSign(t, manager, raw), "\n")
+	header := "gpgsig " + strings.ReplaceAll(sig, "\n", "\n ") + "\n"
+	i := strings.Index(raw, "\n\n") + 1
+	commit := git(t, dir, raw[:i]+header+raw[i:], "hash-object", "-t", "commit", "-w", "--stdin")
+
+	tag := "object " + commit + "\ntype commit\ntag v2.11.0\ntagger Someone Else <a@apache.org> 1647648000 +0800\n\nrelease 2.11.0\n"
+	tagSHA := git(t, dir, tag+armoredSign(t, manager, tag), "hash-object", "-t", "tag", "-w", "--stdin")
+	git(t, dir, "", "update-ref", "refs/tags/v2.11.0", tagSHA)
+
+	d := gitDist(t, writeKeys(t, manager))
+	d.gitDir = dir
+	d.commit = commit
+	d.VerifyGit()
+
+	want := map[string]bool{
+		"git tag v2.11.0 signature":           true,
+		"git tag v2.11.0 tagger is announcer": false, // tagged by someone else
+		"git commit " + commit + " signature": true,
+	}
+	got := results(d.Report())
+	for item, ok := range want {
+		if v, found := got[item]; !found || v != ok {
+			t.Errorf("%s = %v (reported %v), want %v", item, v, found, ok)
+		}
+	}
+
+	// lightweight tag is not signed
+	git(t, dir, "", "tag", "v2.11.1", commit)
+	d = gitDist(t, writeKeys(t, manager))
+	d.rc = Semver{Major: 2, Minor: 11, Patch: 1}
+	d.gitDir = dir
+	d.VerifyGit()
+	if got := results(d.Report()); got["git tag v2.11.1 signature"] {
+		t.Error("lightweight tag expect bad")
+	}
+
+	// unsigned annotated tag skipped like unsigned commit
+	git(t, dir, "", "tag", "-a", "-m", "release 2.11.2", "v2.11.2", commit)
+	d = gitDist(t, writeKeys(t, manager))
+	d.rc = Semver{Major: 2, Minor: 11, Patch: 2}
+	d.gitDir = dir
+	d.VerifyGit()
+	if got := results(d.Report()); len(got) != 1 || !got["git tag v2.11.2 tagger is announcer"] {
+		t.Errorf("unsigned tag results = %v, want tagger only", got)
+	}
+
+	// verification not enabled
+	d = gitDist(t, writeKeys(t, manager))
+	d.gitDir, d.verifyGit = dir, false
+	d.VerifyGit()
+	if got := results(d.Report()); len(got) != 0 {
+		t.Errorf("results = %v, want none", got)
+	}
+}
+
+func TestIsAnnouncer(t *testing.T) {
+	keyring := openpgp.EntityList{newSigner(t, "Zeping Bai")}
+	tests := []struct {
+		name   string
+		signer string
+		email  string
+		want   bool
+	}{
+		{name: "same name", signer: "Zeping Bai", email: "release@apache.org", want: true},
+		{name: "different case", signer: "zeping BAI", email: "Release@Apache.org", want: true},
+		{name: "without email", signer: "Zeping Bai", want: true},
+		{name: "prefix of name", signer: "Zeping Bai Jr"},
+		{name: "announcer prefix", signer: "Zeping"},
+		{name: "email of another", signer: "Zeping Bai", email: "someone@apache.org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isAnnouncer(keyring, &signedObject{signer: tt.signer, email: tt.email}, "Zeping Bai")
+			if got != tt.want || (err != nil) == tt.want {
+				t.Errorf("isAnnouncer() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignedBy(t *testing.T) {
+	signer := newSigner(t, "Mingxyz Other")
+	tests := []struct {
+		announcer string
+		want      bool
+	}{
+		{announcer: "Mingxyz Other", want: true},
+		{announcer: "mingxyz other", want: true},
+		{announcer: "Ming"},
+		{announcer: "Mingxyz Other <release@apache.org>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.announcer, func(t *testing.T) {
+			if got := signedBy(signer, tt.announcer); got != tt.want {
+				t.Errorf("signedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	checkIncubator = "incubator"
+
+	incubatingSuffix = "-incubating"
+	// disclaimerText sentence shared by standard DISCLAIMER and DISCLAIMER-WIP
+	disclaimerText = "is an effort undergoing incubation at the apache software foundation"
+)
+
+// normalizeText lower case with single spaces, line breaks never split phrases
+func normalizeText(body []byte) string {
+	return strings.Join(strings.Fields(strings.ToLower(string(body))), " ")
+}
+
+// incubatorCheck podling release policy: -incubating names, DISCLAIMER with
+// standard text, and NOTICE mentions incubating
+type incubatorCheck struct {
+	dist *Dist
+	top  string
+	tops map[string]bool // top-level entry names of source package
+
+	disclaimer string // DISCLAIMER or DISCLAIMER-WIP found
+	standard   bool
+	notice     bool
+}
+
+func newIncubatorCheck(d *Dist) *incubatorCheck {
+	c := &incubatorCheck{dist: d, tops: map[string]bool{}}
+	if top := entryName(d.TopDir()); top != "." && top != "" {
+		c.top = top
+	}
+
+	return c
+}
+
+// names dist directory and artifacts under it as listed by dist, or by the
+// local directory when verifying offline, with the single top directory of
+// source package if any
+func (c *incubatorCheck) names() ([]string, error) {
+	d := c.dist
+	var files []string
+	if d.offline {
+		entries, err := os.ReadDir(d.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				files = append(files, e.Name())
+			}
+		}
+	} else {
+		body, err := d.Linker.Get(d.PackageLink())
+		if err != nil {
+			return nil, err
+		}
+		files = fileListing(body)
+	}
+
+	names := append([]string{d.Package()}, files...)
+	if len(c.tops) == 1 {
+		for top := range c.tops {
+			names = append(names, top)
+		}
+	}
+	return names, nil
+}
+
+func (c *incubatorCheck) Begin(archive string) error {
+	return nil
+}
+
+func (c *incubatorCheck) Entry(hdr *tar.Header, r io.Reader) error {
+	name := entryName(hdr.Name)
+	if i := strings.Index(name, "/"); i > 0 {
+		c.tops[name[:i]] = true
+	} else if hdr.Typeflag == tar.TypeDir {
+		c.tops[name] = true
+	} else {
+		// file at archive root, no single top directory
+		c.tops[""], c.tops[name] = true, true
+	}
+	if hdr.Typeflag != tar.TypeReg {
+		return nil
+	}
+
+	rel, ok := relToTop(c.top, entryName(hdr.Name))
+	if !ok {
+		return nil
+	}
+
+	switch rel {
+	case "DISCLAIMER", "DISCLAIMER-WIP":
+		body, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		c.disclaimer = rel
+		c.standard = strings.Contains(normalizeText(body), disclaimerText)
+	case "NOTICE":
+		body, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		c.notice = strings.Contains(normalizeText(body), "incubating")
+	}
+
+	return nil
+}
+
+func (c *incubatorCheck) End() []Result {
+	item := "incubator names without " + incubatingSuffix
+	names, err := c.names()
+	var plain []string
+	for _, name := range names {
+		if !strings.Contains(name, incubatingSuffix) {
+			plain = append(plain, name)
+		}
+	}
+	named := listed(item, plain)
+	if err != nil {
+		named = Result{Item: item, Kind: errorKind(err), Detail: err.Error()}
+	}
+
+	disclaimer := Result{Item: "incubator DISCLAIMER", OK: c.standard}
+	switch {
+	case c.disclaimer == "":
+		disclaimer.Detail = "neither DISCLAIMER nor DISCLAIMER-WIP found"
+	case !c.standard:
+		disclaimer.Detail = fmt.Sprintf("%s without standard text", c.disclaimer)
+	}
+
+	return []Result{
+		named,
+		disclaimer,
+		{Item: "incubator NOTICE mentions incubating", OK: c.notice},
+	}
+}
+
+func init() {
+	RegisterArchiveCheck(checkIncubator, func(d *Dist) ArchiveCheck {
+		return newIncubatorCheck(d)
+	})
+}
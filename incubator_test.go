@@ -0,0 +1,234 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const disclaimer = `Apache Foo is an effort undergoing incubation at The Apache
+Software Foundation (ASF), sponsored by the Apache Incubator.`
+
+// podlingNaming naming of podling foo, -incubating follows version
+var podlingNaming = Naming{
+	Dir:     "{pkg}-{version}-incubating",
+	Src:     "{prefix}-{pkg}-{version}-incubating",
+	Archive: "-src.tgz",
+	Top:     "{prefix}-{pkg}-{version}-incubating-src",
+}
+
+// newFooDist definition of podling foo
+func newFooDist() *Dist {
+	return &Dist{
+		Candidate: Candidate{
+			pkg:        "foo",
+			rc:         candidateVersion(),
+			pkgPrefix:  prefixApache,
+			naming:     podlingNaming,
+			project:    "foo",
+			incubating: true,
+		},
+		announcer: announcer,
+		repo:      "incubator-foo",
+		Linker:    newLinker(),
+		checks:    defaultChecks,
+		report:    &Report{},
+	}
+}
+
+// podling dist of incubating project foo, artifacts under local directory
+// named by dist directory naming
+func podling(t *testing.T, naming Naming) *Dist {
+	d := newFooDist()
+	d.rc = Semver{Major: 1, Minor: 2}
+	d.naming = naming
+	d.offline = true
+	d.dir = filepath.Join(t.TempDir(), d.Package())
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func TestIncubator(t *testing.T) {
+	naming := podlingNaming
+
+	d := podling(t, naming)
+	if got := d.SrcLink(); got != "https://dist.apache.org/repos/dist/dev/incubator/foo/foo-1.2.0-incubating/apache-foo-1.2.0-incubating-src.tgz" {
+		t.Errorf("SrcLink() = %s", got)
+	}
+
+	top := "apache-foo-1.2.0-incubating-src/"
+	tests := []struct {
+		name   string
+		naming Naming
+		files  map[string]string
+		bad    []string
+	}{
+		{
+			name:   "conform",
+			naming: naming,
+			files:  map[string]string{"DISCLAIMER": disclaimer, "NOTICE": "Apache Foo (incubating)"},
+		},
+		{
+			name:   "work in progress",
+			naming: naming,
+			files:  map[string]string{"DISCLAIMER-WIP": "Some of the incubating project's releases may not be fully compliant.\n" + disclaimer, "NOTICE": "Apache Foo (Incubating)"},
+		},
+		{
+			name:   "non-standard disclaimer",
+			naming: naming,
+			files:  map[string]string{"DISCLAIMER": "Use at your own risk.", "NOTICE": "Apache Foo (incubating)"},
+			bad:    []string{"incubator DISCLAIMER"},
+		},
+		{
+			name:   "missing disclaimer",
+			naming: naming,
+			files:  map[string]string{"NOTICE": "Apache Foo"},
+			bad:    []string{"layout DISCLAIMER", "incubator DISCLAIMER", "incubator NOTICE mentions incubating"},
+		},
+		{
+			name:   "plain names",
+			naming: Naming{Dir: "{pkg}-{version}", Src: "{prefix}-{pkg}-{version}", Archive: "-src.tgz", Top: naming.Top},
+			files:  map[string]string{"DISCLAIMER": disclaimer, "NOTICE": "Apache Foo (incubating)"},
+			bad:    []string{"incubator names without -incubating"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := podling(t, tt.naming)
+			files := map[string]string{top + "LICENSE": "Apache License", top + "README.md": "# Foo"}
+			names := []string{top + "LICENSE", top + "README.md"}
+			for name, body := range tt.files {
+				files[top+name] = body
+				names = append(names, top+name)
+			}
+			if err := os.WriteFile(d.path(d.srcArchive()), tgz(t, names, files), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := d.CheckExtras(); err != nil {
+				t.Fatal(err)
+			}
+
+			bad := map[string]bool{}
+			for _, item := range tt.bad {
+				bad[item] = true
+			}
+			for _, res := range d.Report().Results {
+				if res.OK == bad[res.Item] {
+					t.Errorf("%s = %v %s, want %v", res.Item, res.OK, res.Detail, !bad[res.Item])
+				}
+				delete(bad, res.Item)
+			}
+			for item := range bad {
+				t.Errorf("%s not reported", item)
+			}
+		})
+	}
+}
+
+func TestIncubating(t *testing.T) {
+	// podling definition verified by verify command
+	projects["foo"] = newFooDist
+	saved := projectNames
+	projectNames = append(append([]string{}, projectNames...), "foo")
+	announcer = "Zeping Bai"
+	defer func() {
+		delete(projects, "foo")
+		projectNames, announcer = saved, ""
+	}()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "foo-1.2.0-incubating")
+	top := "apache-foo-1.2.0-incubating-src/"
+	names := []string{top + "LICENSE", top + "NOTICE", top + "DISCLAIMER", top + "README.md"}
+	pkg := tgz(t, names, map[string]string{
+		top + "LICENSE":    "Apache License",
+		top + "README.md":  "# Apache Foo",
+		top + "NOTICE":     "Apache Foo (incubating)",
+		top + "DISCLAIMER": disclaimer,
+	})
+	keys := writeRelease(t, dir, "apache-foo-1.2.0-incubating-src.tgz", pkg, newSigner(t, "Zeping Bai"))
+
+	verify := func() *Report {
+		reports, err := VerifyLocal(root, keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reports) != 1 {
+			t.Fatalf("reports = %d, want 1", len(reports))
+		}
+		return reports[0]
+	}
+
+	r := verify()
+	if r.Project != "incubator-foo" {
+		t.Errorf("project = %s, want incubator-foo", r.Project)
+	}
+	names = nil
+	for _, res := range r.Results {
+		if !res.OK {
+			t.Errorf("%s bad %s", res.Item, res.Detail)
+		}
+		names = append(names, res.Item)
+	}
+	if !contains(names, "incubator DISCLAIMER") {
+		t.Errorf("incubator check not run: %v", names)
+	}
+
+	// convenience binary of candidate named without -incubating
+	if err := os.WriteFile(filepath.Join(dir, "apache-foo-1.2.0-bin.zip"), []byte("zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range verify().Results {
+		if res.Item == "incubator names without -incubating" && (res.OK || res.Detail != "apache-foo-1.2.0-bin.zip") {
+			t.Errorf("%s = %v %s, want bad apache-foo-1.2.0-bin.zip", res.Item, res.OK, res.Detail)
+		}
+	}
+}
+
+func TestIncubatorCheck_distListing(t *testing.T) {
+	d := podling(t, podlingNaming)
+	d.offline = false
+	listing := `<html><ul>
+<li><a href="../">..</a></li>
+<li><a href="apache-foo-1.2.0-incubating-src.tgz">apache-foo-1.2.0-incubating-src.tgz</a></li>
+<li><a href="apache-foo-1.2.0-src.tgz.asc">apache-foo-1.2.0-src.tgz.asc</a></li>
+</ul></html>`
+	d.Linker = Linker{timeout: 3, transport: fakeTransport(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != d.PackageLink() {
+			return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader(listing)), Request: req}, nil
+	})}
+
+	c := newIncubatorCheck(d)
+	names, err := c.names()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"foo-1.2.0-incubating", "apache-foo-1.2.0-incubating-src.tgz", "apache-foo-1.2.0-src.tgz.asc"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("names() = %v, want %v", names, want)
+	}
+}
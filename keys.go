@@ -0,0 +1,80 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// readKeyRing read all armored public key blocks, like project KEYS file
+// which puts descriptions between blocks
+func readKeyRing(filename string) (openpgp.EntityList, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// share buffered reader, armor.Decode would drop read ahead of next block
+	r := bufio.NewReader(f)
+	var keyring openpgp.EntityList
+	for {
+		block, err := armor.Decode(r)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if block.Type != openpgp.PublicKeyType {
+			continue
+		}
+
+		entities, err := openpgp.ReadKeyRing(block.Body)
+		if err != nil {
+			return nil, err
+		}
+		keyring = append(keyring, entities...)
+	}
+
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("not found any public key in %s", filename)
+	}
+
+	return keyring, nil
+}
+
+// isAnnouncerName whether name is the announcer's full name regardless of case
+func isAnnouncerName(name, announcer string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(announcer))
+}
+
+// signedBy whether entity has an identity named with announcer
+func signedBy(signer *openpgp.Entity, announcer string) bool {
+	for _, id := range signer.Identities {
+		if isAnnouncerName(id.UserId.Name, announcer) {
+			return true
+		}
+	}
+
+	return false
+}
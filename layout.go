@@ -0,0 +1,146 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const checkLayout = "layout"
+
+// layoutFiles files required inside the top directory, README in any extension
+var layoutFiles = []string{"LICENSE", "NOTICE", "README"}
+
+// incubatorFiles files required additionally for podlings, DISCLAIMER or DISCLAIMER-WIP
+var incubatorFiles = []string{"DISCLAIMER"}
+
+// relToTop path of normalized name relative to top directory, false if outside,
+// empty top means archive root
+func relToTop(top, name string) (string, bool) {
+	if top == "" {
+		return name, true
+	}
+	if topDir(name) != top {
+		return "", false
+	}
+
+	return strings.TrimPrefix(strings.TrimPrefix(name, top), "/"), true
+}
+
+// layoutCheck enforces a single top-level directory named by naming rules,
+// with required files inside it
+type layoutCheck struct {
+	top      string // "" means archive root
+	required []string
+
+	found map[string]bool
+	stray map[string]bool
+	inTop bool
+}
+
+func newLayoutCheck(top string, required []string) *layoutCheck {
+	top = entryName(top)
+	if top == "." {
+		top = ""
+	}
+
+	return &layoutCheck{
+		top:      top,
+		required: required,
+		found:    map[string]bool{},
+		stray:    map[string]bool{},
+	}
+}
+
+func (c *layoutCheck) Begin(archive string) error {
+	return nil
+}
+
+// required file name matched by rel, path relative to top directory
+func (c *layoutCheck) requiredOf(rel string) string {
+	for _, name := range c.required {
+		switch {
+		case rel == name,
+			name == "README" && strings.HasPrefix(rel, "README."),
+			name == "DISCLAIMER" && rel == "DISCLAIMER-WIP":
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (c *layoutCheck) Entry(hdr *tar.Header, r io.Reader) error {
+	if hdr.Typeflag == tar.TypeXGlobalHeader {
+		return nil
+	}
+
+	name := entryName(hdr.Name)
+	if name == "" || name == "." {
+		return nil
+	}
+
+	rel, ok := relToTop(c.top, name)
+	if !ok {
+		c.stray[topDir(name)] = true
+		return nil
+	}
+	c.inTop = true
+
+	if hdr.Typeflag == tar.TypeReg {
+		if req := c.requiredOf(rel); req != "" {
+			c.found[req] = true
+		}
+	}
+
+	return nil
+}
+
+func (c *layoutCheck) End() []Result {
+	var results []Result
+	if c.top != "" {
+		res := Result{Item: fmt.Sprintf("layout top directory %s/", c.top), OK: c.inTop}
+		if !c.inTop {
+			res.Detail = "not found"
+		}
+
+		var stray []string
+		for name := range c.stray {
+			stray = append(stray, name)
+		}
+		sort.Strings(stray)
+		results = append(results, res, listed("layout stray root entries", stray))
+	}
+
+	for _, name := range c.required {
+		results = append(results, Result{Item: "layout " + name, OK: c.found[name]})
+	}
+
+	return results
+}
+
+func init() {
+	RegisterArchiveCheck(checkLayout, func(d *Dist) ArchiveCheck {
+		required := layoutFiles
+		if d.incubating {
+			required = append(append([]string{}, layoutFiles...), incubatorFiles...)
+		}
+		return newLayoutCheck(d.TopDir(), required)
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayoutCheck(t *testing.T) {
+	c := &Candidate{
+		pkg:       pkgAPISixDashboard,
+		rc:        Semver{Major: 2, Minor: 11},
+		pkgPrefix: prefixApache,
+		naming:    dashboardNaming,
+	}
+	c.naming.Top = "{prefix}-{pkg}-{version}-src"
+	top := c.TopDir()
+	if top != "apache-apisix-dashboard-2.11.0-src" {
+		t.Fatalf("TopDir() = %s", top)
+	}
+
+	tests := []struct {
+		name  string
+		top   string
+		files []string
+		bad   map[string]string // item -> detail
+	}{
+		{
+			name:  "conform",
+			top:   top,
+			files: []string{top + "/LICENSE", top + "/NOTICE", top + "/README.md", top + "/web/main.go"},
+		},
+		{
+			name:  "root files",
+			top:   top,
+			files: []string{"LICENSE", "NOTICE", "README.md"},
+			bad: map[string]string{
+				"layout top directory " + top + "/": "not found",
+				"layout stray root entries":         "LICENSE, NOTICE, README.md",
+				"layout LICENSE":                    "",
+				"layout NOTICE":                     "",
+				"layout README":                     "",
+			},
+		},
+		{
+			name:  "stray and nested",
+			top:   top,
+			files: []string{top + "/LICENSE", top + "/docs/NOTICE", top + "/README", "apisix-dashboard/main.go"},
+			bad: map[string]string{
+				"layout stray root entries": "apisix-dashboard",
+				"layout NOTICE":             "",
+			},
+		},
+		{
+			name:  "archive root",
+			top:   ".",
+			files: []string{"./LICENSE", "./NOTICE", "./README.md", "./api/main.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hdrs []*tar.Header
+			for _, name := range tt.files {
+				hdrs = append(hdrs, &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg})
+			}
+			filename := filepath.Join(t.TempDir(), "pkg-src.tgz")
+			if err := os.WriteFile(filename, tgzHeaders(t, hdrs), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			check := newLayoutCheck(tt.top, layoutFiles)
+			if _, err := scanArchive(filename, nil, []entryVisitor{check.Entry}); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, res := range check.End() {
+				detail, bad := tt.bad[res.Item]
+				if res.OK == bad || res.Detail != detail {
+					t.Errorf("%s = %v %q, want %v %q", res.Item, res.OK, res.Detail, !bad, detail)
+				}
+			}
+		})
+	}
+}
@@ -15,15 +15,36 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
-
-	"github.com/parnurzeal/gorequest"
 )
 
 // Linker validate link
 type Linker struct {
-	timeout uint
+	timeout   uint
+	workers   int
+	retrier   Retrier
+	transport http.RoundTripper
+	cache     *Cache
+	ctx       context.Context
+}
+
+// newLinker linker configured by global flags
+func newLinker() Linker {
+	return Linker{
+		timeout: timeout,
+		workers: int(concurrency),
+		retrier: Retrier{
+			Retries: retries,
+			Backoff: retryBackoff,
+		},
+		transport: transport,
+		cache:     downloadCache,
+		ctx:       runCtx,
+	}
 }
 
 // SetTimeout set timeout when request
@@ -31,28 +52,122 @@ func (l *Linker) SetTimeout(timeout uint) {
 	l.timeout = timeout
 }
 
-// Head use http.HEAD to valid
+// SetTransport set transport of requests, like fake one in tests
+func (l *Linker) SetTransport(t http.RoundTripper) {
+	l.transport = t
+}
+
+// SetContext set context of requests, requests abort once it's done
+func (l *Linker) SetContext(ctx context.Context) {
+	l.ctx = ctx
+}
+
+func (l *Linker) context() context.Context {
+	if l.ctx == nil {
+		return context.Background()
+	}
+
+	return l.ctx
+}
+
+func (l *Linker) request(method, link string) (*http.Request, error) {
+	return http.NewRequestWithContext(l.context(), method, link, nil)
+}
+
+func (l *Linker) client() *http.Client {
+	t := l.transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+
+	return &http.Client{Transport: t, Timeout: time.Duration(l.timeout) * time.Second}
+}
+
+// head request link with retries, non-ok status returns as *StatusError
+func (l *Linker) head(link string) (*http.Response, error) {
+	var res *http.Response
+
+	err := l.retrier.Do(l.context(), func() error {
+		req, err := l.request(http.MethodHead, link)
+		if err != nil {
+			return err
+		}
+		if res, err = l.client().Do(req); err != nil {
+			return &UnreachableError{Link: link, Err: err}
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return newStatusError(link, res)
+		}
+		return nil
+	})
+
+	return res, err
+}
+
+// Head use http.HEAD to valid, non-ok status returns as *StatusError
 func (l *Linker) Head(link string) (bool, error) {
-	var valid bool
-	var err error
+	_, err := l.head(link)
+	return err == nil, err
+}
+
+// Get use http.GET to fetch link content
+func (l *Linker) Get(link string) (string, error) {
+	return l.GetWithHeader(link, nil)
+}
 
-	r := gorequest.New()
-	sa := r.Timeout(time.Duration(l.timeout) * time.Second)
+// GetWithHeader fetch link content with extra request header, like API token
+func (l *Linker) GetWithHeader(link string, header http.Header) (string, error) {
+	var content string
 
-	sa.Head(link).End(func(res gorequest.Response, body string, errs []error) {
-		for _, e := range errs {
-			if e != nil {
-				err = e
-			}
+	err := l.retrier.Do(l.context(), func() error {
+		req, err := l.request(http.MethodGet, link)
+		if err != nil {
+			return err
+		}
+		for k, v := range header {
+			req.Header[k] = v
 		}
+		res, err := l.client().Do(req)
 		if err != nil {
-			return
+			return &UnreachableError{Link: link, Err: err}
 		}
+		defer res.Body.Close()
 
-		if res.StatusCode == http.StatusOK {
-			valid = true
+		if res.StatusCode != http.StatusOK {
+			return newStatusError(link, res)
 		}
+
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return &UnreachableError{Link: link, Err: err}
+		}
+
+		content = string(body)
+		return nil
 	})
 
-	return valid, err
+	return content, err
+}
+
+// A linkCheck represents a link validated as kind, like github or dist
+type linkCheck struct {
+	kind string
+	link string
+}
+
+// HeadAll validate links concurrently by workers, results recorded in the
+// order of links, returns the first unreachable error
+func (l *Linker) HeadAll(checks []linkCheck, report *Report) error {
+	var items []itemCheck
+	for _, c := range checks {
+		link := c.link
+		items = append(items, itemCheck{
+			item: fmt.Sprintf("%s %s validate", c.kind, link),
+			fn:   func() (bool, error) { return l.Head(link) },
+		})
+	}
+
+	return runItemChecks(l.workers, items, report)
 }
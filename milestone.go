@@ -0,0 +1,180 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// prNumber pull request reference in CHANGELOG, like (#2301) or apache/apisix#2301
+var prNumber = regexp.MustCompile(`#(\d+)\b`)
+
+// A Milestone tracks issues and pull requests of a release
+type Milestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	State  string `json:"state"`
+}
+
+// An Issue is an issue or a pull request attached to milestone
+type Issue struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	State       string `json:"state"`
+	PullRequest *struct {
+		MergedAt string `json:"merged_at"`
+	} `json:"pull_request"`
+}
+
+// IsPull whether issue is a pull request
+func (i Issue) IsPull() bool {
+	return i.PullRequest != nil
+}
+
+// Merged whether issue is a merged pull request
+func (i Issue) Merged() bool {
+	return i.PullRequest != nil && i.PullRequest.MergedAt != ""
+}
+
+// Milestone milestone of title, v prefix ignored, open and closed ones searched
+func (a *GitHubAPI) Milestone(title string) (*Milestone, error) {
+	want := strings.TrimPrefix(title, "v")
+	for page := 1; ; page++ {
+		var milestones []Milestone
+		query := url.Values{"state": {"all"}, "per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		if err := a.get("/milestones?"+query.Encode(), &milestones); err != nil {
+			return nil, err
+		}
+
+		for i, m := range milestones {
+			if strings.TrimPrefix(strings.TrimSpace(m.Title), "v") == want {
+				return &milestones[i], nil
+			}
+		}
+		if len(milestones) < 100 {
+			return nil, fmt.Errorf("milestone %s not found", title)
+		}
+	}
+}
+
+// MilestoneIssues issues and pull requests attached to milestone, all pages
+func (a *GitHubAPI) MilestoneIssues(number int) ([]Issue, error) {
+	var issues []Issue
+	for page := 1; ; page++ {
+		var list []Issue
+		query := url.Values{"milestone": {fmt.Sprint(number)}, "state": {"all"}, "per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		if err := a.get("/issues?"+query.Encode(), &list); err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, list...)
+		if len(list) < 100 {
+			return issues, nil
+		}
+	}
+}
+
+// changelogPRs pull request numbers referenced in CHANGELOG section
+func changelogPRs(section string) map[int]bool {
+	prs := make(map[int]bool)
+	for _, m := range prNumber.FindAllStringSubmatch(section, -1) {
+		var n int
+		if _, err := fmt.Sscan(m[1], &n); err == nil {
+			prs[n] = true
+		}
+	}
+
+	return prs
+}
+
+// issueRefs #numbers of issues in ascending order
+func issueRefs(numbers []int) []string {
+	sort.Ints(numbers)
+	refs := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		refs = append(refs, fmt.Sprintf("#%d", n))
+	}
+
+	return refs
+}
+
+// ValidMilestone report open issues and pull requests still attached to
+// milestone of release, and merged pull requests missing from CHANGELOG
+// section. Returns error only if API unreachable, like ValidAPI
+func (g *GitHub) ValidMilestone(api *GitHubAPI) error {
+	release := g.git.Release
+	item := fmt.Sprintf("github milestone %s exists", release)
+	milestone, err := api.Milestone(release)
+	if err == nil {
+		var issues []Issue
+		issues, err = api.MilestoneIssues(milestone.Number)
+		if err == nil {
+			g.report.Record(item, true, nil)
+			return g.validMilestoneIssues(api, issues)
+		}
+	}
+
+	g.report.Record(item, false, err)
+	if isUnreachable(err) {
+		return err
+	}
+	return nil
+}
+
+// validMilestoneIssues open issues of milestone, merged pull requests
+// cross-referenced with CHANGELOG section
+func (g *GitHub) validMilestoneIssues(api *GitHubAPI, issues []Issue) error {
+	release := g.git.Release
+	var open, merged []int
+	for _, issue := range issues {
+		switch {
+		case issue.State == "open":
+			open = append(open, issue.Number)
+		case issue.Merged():
+			merged = append(merged, issue.Number)
+		}
+	}
+	g.report.RecordResult(listed(fmt.Sprintf("github milestone %s has no open issues or pull requests", release), issueRefs(open)))
+
+	item := fmt.Sprintf("github milestone %s merged pull requests in CHANGELOG", release)
+	body, err := api.Content("CHANGELOG.md", g.changelogRef())
+	if err != nil {
+		g.report.Record(item, false, err)
+		if isUnreachable(err) {
+			return err
+		}
+		return nil
+	}
+
+	section, ok := changelogSection(body, g.git.MarkdownID())
+	if !ok {
+		g.report.Record(item, false, fmt.Errorf("CHANGELOG section %s not found", g.git.MarkdownID()))
+		return nil
+	}
+
+	prs := changelogPRs(section)
+	var missing []int
+	for _, n := range merged {
+		if !prs[n] {
+			missing = append(missing, n)
+		}
+	}
+	g.report.RecordResult(listed(item, issueRefs(missing)))
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import "testing"
+
+// milestoneAPI milestone 2.11.0 with issues, CHANGELOG of dashboardAPI
+func milestoneAPI() map[string]interface{} {
+	repo := "/repos/apache/apisix-dashboard"
+	merged := map[string]string{"merged_at": "2022-03-10T08:00:00Z"}
+	responses := dashboardAPI("ahead")
+	responses[repo+"/milestones?page=1&per_page=100&state=all"] = []map[string]interface{}{
+		{"number": 6, "title": "2.10.1", "state": "closed"},
+		{"number": 7, "title": "v2.11.0", "state": "open"},
+	}
+	responses[repo+"/issues?milestone=7&page=1&per_page=100&state=all"] = []map[string]interface{}{
+		{"number": 2301, "state": "closed", "pull_request": merged},
+		{"number": 2310, "state": "closed", "pull_request": merged},
+		{"number": 2320, "state": "closed", "pull_request": merged},
+		{"number": 2330, "state": "open"},
+		{"number": 2340, "state": "closed", "pull_request": map[string]interface{}{"merged_at": nil}},
+		{"number": 2350, "state": "closed"},
+	}
+	return responses
+}
+
+func TestGitHub_ValidMilestone(t *testing.T) {
+	srv := fakeGitHub(t, "", milestoneAPI())
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		release string
+		want    []Result
+	}{
+		{
+			name:    "milestone",
+			release: "2.11.0",
+			want: []Result{
+				{Item: "github milestone 2.11.0 exists", OK: true},
+				{Item: "github milestone 2.11.0 has no open issues or pull requests", Detail: "#2330"},
+				{Item: "github milestone 2.11.0 merged pull requests in CHANGELOG", Detail: "#2320"},
+			},
+		},
+		{
+			name:    "no milestone",
+			release: "2.12.0",
+			want: []Result{
+				{Item: "github milestone 2.12.0 exists", Detail: "milestone 2.12.0 not found"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := &Git{Repo: pkgAPISixDashboard, Release: tt.release, Branch: "release/2.11"}
+			api := NewGitHubAPI(srv.URL, git.Repo)
+			api.Linker = Linker{timeout: 3, workers: 4}
+
+			g := &GitHub{git: git, report: &Report{}}
+			if err := g.ValidMilestone(api); err != nil {
+				t.Fatalf("ValidMilestone() error = %v", err)
+			}
+			if len(g.report.Results) != len(tt.want) {
+				t.Fatalf("results = %+v, want %d", g.report.Results, len(tt.want))
+			}
+			for i, res := range g.report.Results {
+				if res.Item != tt.want[i].Item || res.OK != tt.want[i].OK || res.Detail != tt.want[i].Detail {
+					t.Errorf("result %d = %+v, want %+v", i, res, tt.want[i])
+				}
+			}
+		})
+	}
+}
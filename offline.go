@@ -0,0 +1,129 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	offlineDir  string
+	offlineKeys string
+)
+
+// localDist match source package file against projects' naming rules
+func localDist(filename string) *Dist {
+	base := filepath.Base(filename)
+	for _, name := range projectNames {
+		d := dist(name)
+		if !strings.HasSuffix(base, d.naming.Archive) {
+			continue
+		}
+		if rc, ok := d.MatchSrc(strings.TrimSuffix(base, d.naming.Archive)); ok {
+			d.rc = rc
+			d.dir = filepath.Dir(filename)
+			d.offline = true
+			return d
+		}
+	}
+
+	return nil
+}
+
+// discoverArtifacts find source packages under dir recursively,
+// like a svn checkout of dist dev directory
+func discoverArtifacts(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if e.IsDir() && e.Name() == ".svn" {
+			return filepath.SkipDir
+		}
+		if !e.IsDir() && isArchive(e.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// VerifyLocal verify artifacts under dir with KEYS, without any request
+func VerifyLocal(dir, keys string) ([]*Report, error) {
+	files, err := discoverArtifacts(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("not found any source package under %s", dir)
+	}
+
+	var reports []*Report
+	for _, f := range files {
+		d := localDist(f)
+		if d == nil {
+			log.Printf("skip %s, not match any project\n", f)
+			continue
+		}
+
+		log.Printf("verify %s %s from %s\n", d.repo, d.rc, d.dir)
+		d.keys = keys
+		d.Verify()
+		reports = append(reports, d.Report())
+	}
+
+	return reports, nil
+}
+
+func bindOfflineFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&offlineDir, "dir", "d", "", "Specify directory of release artifacts, like svn checkout of dist")
+	flags.StringVarP(&offlineKeys, "keys", "k", "", "Specify KEYS file of project")
+	bindReportFlags(flags)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify local release artifacts offline",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if offlineDir == "" || offlineKeys == "" {
+			return fmt.Errorf("both --dir and --keys required")
+		}
+		if _, err := os.Stat(offlineKeys); err != nil {
+			return err
+		}
+
+		reports, err := VerifyLocal(offlineDir, offlineKeys)
+		if err != nil {
+			return err
+		}
+
+		return summarize(reports)
+	},
+}
+
+func init() {
+	bindOfflineFlags(verifyCmd.Flags())
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// writeRelease write package with its sha512 and asc into dir,
+// returns KEYS file of signer
+func writeRelease(t *testing.T, dir, name string, pkg []byte, signer *openpgp.Entity) string {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), pkg, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := fmt.Sprintf("%x  %s\n", sha512.Sum512(pkg), name)
+	if err := os.WriteFile(filepath.Join(dir, name+".sha512"), []byte(sum), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var asc bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&asc, signer, bytes.NewReader(pkg), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".asc"), asc.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return writeKeys(t, signer)
+}
+
+// writeKeys write KEYS file of signers, descriptions between key blocks
+func writeKeys(t *testing.T, signers ...*openpgp.Entity) string {
+	var keys bytes.Buffer
+	keys.WriteString("This file contains the PGP keys of various developers.\n")
+	for _, signer := range signers {
+		keys.WriteString("\npub   rsa4096 2022-03-19\n")
+		w, err := armor.Encode(&keys, openpgp.PublicKeyType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := signer.Serialize(w); err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+	}
+
+	filename := filepath.Join(t.TempDir(), "KEYS")
+	if err := os.WriteFile(filename, keys.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+func newSigner(t *testing.T, name string) *openpgp.Entity {
+	e, err := openpgp.NewEntity(name, "", "release@apache.org", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestVerifyLocal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "apisix-dashboard-2.11.0")
+	pkg := tgz(t, []string{"LICENSE", "NOTICE", "README.md"},
+		map[string]string{"LICENSE": "Apache License", "NOTICE": "Apache APISIX", "README.md": "# Apache APISIX Dashboard"})
+	keys := writeRelease(t, dir, "apache-apisix-dashboard-2.11.0-src.tgz", pkg, newSigner(t, "Zeping Bai"))
+
+	announcer = "Zeping Bai"
+	defer func() { announcer = "" }()
+
+	reports, err := VerifyLocal(root, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("reports = %d, want 1", len(reports))
+	}
+
+	r := reports[0]
+	if r.Project != pkgAPISixDashboard || r.Candidate != "2.11.0" || !r.Passed() {
+		var buf bytes.Buffer
+		r.Summary(&buf)
+		t.Errorf("report not passed:\n%s", buf.String())
+	}
+
+	// signed by another key
+	writeRelease(t, dir, "apache-apisix-dashboard-2.11.0-src.tgz", pkg, newSigner(t, "Someone Else"))
+	reports, err = VerifyLocal(root, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reports[0].Passed() {
+		t.Error("signature by unknown key expect bad")
+	}
+}
@@ -14,7 +14,11 @@
 //
 package main
 
-import "github.com/spf13/pflag"
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
 
 var (
 	// Version marked to show version
@@ -27,6 +31,27 @@ var (
 	commitID  string
 	announcer string
 	timeout   uint
+	latest    bool
+
+	retries      uint
+	retryBackoff time.Duration
+	concurrency  uint
+	deadline     time.Duration
+
+	transportOpts TransportOptions
+	recordDir     string
+	replayDir     string
+
+	workdir string
+	keep    bool
+
+	cacheDir   string
+	cacheLimit uint
+	noCache    bool
+
+	keysFile  string
+	gitDir    string
+	verifyGit bool
 
 	enableGithub bool
 	enableDist   bool
@@ -41,9 +66,32 @@ func BindVerFlags(flags *pflag.FlagSet) {
 // BindGlobalFlags bind persistent flags
 func BindGlobalFlags(flags *pflag.FlagSet) {
 	flags.UintVarP(&timeout, "timeout", "t", 0, "Specify request link timeout, unit: second")
+	flags.UintVarP(&retries, "retries", "", 3, "Specify retry count of request on transient failure")
+	flags.DurationVarP(&retryBackoff, "retry-backoff", "", time.Second, "Specify first retry backoff, doubled each retry, zero retries without waiting")
+	flags.UintVarP(&concurrency, "concurrency", "j", 4, "Specify number of concurrent requests of a candidate")
+	flags.DurationVarP(&deadline, "deadline", "", 0, "Specify overall deadline of the run, like 5m, zero means none")
+	flags.StringVarP(&transportOpts.Proxy, "proxy", "", "", "Specify proxy URL, defaults to HTTPS_PROXY environment")
+	flags.StringVarP(&transportOpts.CAFile, "ca-file", "", "", "Specify extra CA bundle file in PEM")
+	flags.StringVarP(&transportOpts.CertFile, "cert-file", "", "", "Specify client certificate file in PEM")
+	flags.StringVarP(&transportOpts.KeyFile, "key-file", "", "", "Specify client certificate key file in PEM")
+	flags.BoolVarP(&transportOpts.Insecure, "insecure-skip-verify", "", false, "Skip server certificate verification, only for local mirrors")
+	flags.StringVarP(&transportOpts.UserAgent, "user-agent", "", "sixer/"+version, "Specify User-Agent of requests")
+	flags.StringVarP(&workdir, "workdir", "", "", "Specify workspace directory, defaults to a unique temporary one")
+	flags.BoolVarP(&keep, "keep", "", false, "Keep downloaded files in workspace")
+	flags.StringVarP(&cacheDir, "cache-dir", "", "", "Specify download cache directory, defaults to $XDG_CACHE_HOME/sixer")
+	flags.UintVarP(&cacheLimit, "cache-limit", "", 2048, "Specify download cache size limit, unit: MiB, zero means unlimited")
+	flags.BoolVarP(&noCache, "no-cache", "", false, "Disable download cache")
+	flags.StringVarP(&recordDir, "record", "", "", "Specify cassette directory to record every HTTP interaction")
+	flags.StringVarP(&replayDir, "replay", "", "", "Specify cassette directory to replay HTTP interactions without network")
 	flags.StringVarP(&candidate, "candidate", "c", "", "Specify release candidate version,like 0.2.0")
 	flags.StringVarP(&announcer, "announcer", "a", "", "Specify release candidate announcer")
 	flags.StringVarP(&commitID, "commit", "C", "", "Specify release commit id")
+	flags.StringVarP(&githubAPI, "github-api", "", githubAPILink, "Specify GitHub API base URL, authorized by GITHUB_TOKEN environment, empty disables API checks")
+	flags.StringVarP(&releaseArea, "dist-release", "", distReleaseLink, "Specify dist release area base URL")
+	flags.StringVarP(&archiveArea, "dist-archive", "", archiveDistLink, "Specify dist archive base URL")
+	flags.StringVarP(&downloadsArea, "downloads", "", downloadsLink, "Specify downloads site base URL mirroring release area")
+	flags.StringSliceVarP(&requiredCI, "require-ci", "", nil, "Specify CI checks which must have succeeded on commit, extends project definition")
+	flags.BoolVarP(&latest, "latest", "", false, "Verify the latest release candidate under dist if candidate not specified")
 }
 
 func bindLinkFlags(flags *pflag.FlagSet) {
@@ -54,3 +102,17 @@ func bindLinkFlags(flags *pflag.FlagSet) {
 func bindExtraFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&blob, "blob", "b", "", "Specify release blob, like v1.4.0")
 }
+
+func bindSignFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&keysFile, "keys", "k", "", "Specify project KEYS file verifies signatures, defaults to KEYS under dist for git signatures")
+	flags.BoolVarP(&verifyGit, "verify-git", "", false, "Verify signatures of release tag and commit against project KEYS")
+	flags.StringVarP(&gitDir, "git-dir", "", "", "Specify local clone to read release tag and commit, defaults to GitHub API, implies --verify-git")
+}
+
+func bindArchiveFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&compareGitHub, "compare-github", "", false, "Compare src archive with GitHub archive of release tag")
+	flags.StringVarP(&githubArchiveFile, "github-archive", "", "", "Specify local GitHub archive of release tag to compare with, implies --compare-github")
+	flags.StringSliceVarP(&generatedFiles, "generated", "", nil, "Specify patterns of files generated when packaging, skipped comparing with GitHub archive, extends project definition")
+	flags.BoolVarP(&diffPrevious, "diff-previous", "", false, "Diff src archive against previous final release")
+	flags.StringVarP(&previousFile, "previous", "", "", "Specify local src archive of previous release to diff against, implies --diff-previous")
+}
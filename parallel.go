@@ -0,0 +1,88 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	// runCtx context of the run, done once deadline exceeded
+	runCtx    = context.Background()
+	cancelRun = func() {}
+)
+
+// initDeadline start overall deadline of the run from flags
+func initDeadline() {
+	if deadline > 0 {
+		runCtx, cancelRun = context.WithTimeout(context.Background(), deadline)
+	}
+}
+
+// parallel call fn with index 0 to n-1 by at most workers goroutines,
+// fn stores its result by index so that callers keep the order
+func parallel(workers, n int, fn func(i int)) {
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				fn(j)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// An itemCheck represents a report item verified by fn
+type itemCheck struct {
+	item string
+	fn   func() (bool, error)
+}
+
+// runItemChecks run checks concurrently by workers, results recorded in
+// the order of checks, returns the first unreachable error
+func runItemChecks(workers int, checks []itemCheck, report *Report) error {
+	oks := make([]bool, len(checks))
+	errs := make([]error, len(checks))
+	parallel(workers, len(checks), func(i int) {
+		oks[i], errs[i] = checks[i].fn()
+	})
+
+	var unreachable error
+	for i, c := range checks {
+		report.Record(c.item, oks[i], errs[i])
+		if unreachable == nil && isUnreachable(errs[i]) {
+			unreachable = errs[i]
+		}
+	}
+
+	return unreachable
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	var running, peak int32
+	got := make([]int, 20)
+	parallel(3, len(got), func(i int) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		got[i] = i * i
+		atomic.AddInt32(&running, -1)
+	})
+
+	for i, v := range got {
+		if v != i*i {
+			t.Errorf("got[%d] = %d, want %d", i, v, i*i)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("peak workers = %d, want at most 3", peak)
+	}
+}
+
+func TestLinker_HeadAll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/busy":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	t.Run("ordered", func(t *testing.T) {
+		var checks []linkCheck
+		for _, path := range []string{"/slow", "/missing", "/ok"} {
+			checks = append(checks, linkCheck{kind: "dist", link: srv.URL + path})
+		}
+
+		r := &Report{}
+		l := &Linker{timeout: 3, workers: 3}
+		if err := l.HeadAll(checks, r); err != nil {
+			t.Fatalf("HeadAll() error = %v", err)
+		}
+
+		want := []bool{true, false, true}
+		if len(r.Results) != len(want) {
+			t.Fatalf("results = %d, want %d", len(r.Results), len(want))
+		}
+		for i, res := range r.Results {
+			item := fmt.Sprintf("dist %s validate", checks[i].link)
+			if res.Item != item || res.OK != want[i] {
+				t.Errorf("results[%d] = %s %v, want %s %v", i, res.Item, res.OK, item, want[i])
+			}
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		l := &Linker{timeout: 3, retrier: Retrier{Retries: 3, Backoff: time.Hour}}
+		l.SetContext(ctx)
+
+		start := time.Now()
+		if ok, _ := l.Head(srv.URL + "/busy"); ok {
+			t.Errorf("Head() = true, want false")
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Head() took %s beyond deadline", elapsed)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+var (
+	releaseArea  string
+	archiveArea  string
+	diffPrevious bool
+	previousFile string
+)
+
+// thirdPartyDirs directories which usually hold code copied from elsewhere
+var thirdPartyDirs = []string{"vendor", "third_party", "third-party", "thirdparty", "node_modules", "deps", "external"}
+
+// A ReleaseSummary summarizes changes of source archive since previous release
+type ReleaseSummary struct {
+	Previous     string             `json:"previous"`
+	Added        int                `json:"added"`
+	Removed      int                `json:"removed"`
+	Modified     int                `json:"modified"`
+	ThirdParty   []string           `json:"thirdParty,omitempty"`
+	Binaries     []string           `json:"binaries,omitempty"`
+	Legal        []string           `json:"legal,omitempty"`
+	Dependencies []DependencyChange `json:"dependencies,omitempty"` // new ones only
+}
+
+// isThirdParty whether file looks like third-party code, vendored or minified
+func isThirdParty(name string) bool {
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		if contains(thirdPartyDirs, dir) {
+			return true
+		}
+	}
+
+	return strings.HasSuffix(name, ".min.js") || strings.HasSuffix(name, ".min.css")
+}
+
+// Summarize new third-party code, new binary files, LICENSE/NOTICE changes
+// and new dependencies of the diff
+func (r *ReleaseDiff) Summarize() *ReleaseSummary {
+	s := &ReleaseSummary{
+		Previous: r.From,
+		Added:    len(r.Added),
+		Removed:  len(r.Removed),
+		Modified: len(r.Modified),
+		Legal:    r.Legal,
+	}
+
+	for _, name := range r.Added {
+		if isThirdParty(name) {
+			s.ThirdParty = append(s.ThirdParty, name)
+		}
+		if r.to.files[name].binary {
+			s.Binaries = append(s.Binaries, name)
+		}
+	}
+	for _, c := range r.Dependencies {
+		if c.From == "" {
+			s.Dependencies = append(s.Dependencies, c)
+		}
+	}
+
+	return s
+}
+
+// Write plain text of summary, indented under report summary
+func (s *ReleaseSummary) Write(w io.Writer) {
+	fmt.Fprintf(w, "  since %s: %d added, %d removed, %d modified\n", s.Previous, s.Added, s.Removed, s.Modified)
+	line := func(title string, names []string) {
+		if len(names) > 0 {
+			fmt.Fprintf(w, "    %s: %s\n", title, listDetail(names))
+		}
+	}
+	line("new third-party code", s.ThirdParty)
+	line("new binary files", s.Binaries)
+	line("LICENSE/NOTICE changed", s.Legal)
+
+	var deps []string
+	for _, c := range s.Dependencies {
+		deps = append(deps, c.String())
+	}
+	line("new dependencies", deps)
+}
+
+// previousRelease the newest final release before candidate, looked up in
+// release area then archive, returns its version and area base URL
+func (d *Dist) previousRelease() (Semver, string, error) {
+	for _, base := range []string{d.releaseArea, d.archiveArea} {
+		if base == "" {
+			continue
+		}
+
+		body, err := d.Linker.Get(d.AreaLink(base))
+		if err != nil {
+			if isUnreachable(err) {
+				return Semver{}, "", err
+			}
+			continue
+		}
+
+		var versions []Semver
+		for _, dir := range listing(body) {
+			if v, ok := d.MatchPackage(dir); ok && v.RC == 0 && v.Pre == "" && v.Compare(d.rc) < 0 {
+				versions = append(versions, v)
+			}
+		}
+		if len(versions) > 0 {
+			sort.Slice(versions, func(i, j int) bool {
+				return versions[i].Compare(versions[j]) < 0
+			})
+			return versions[len(versions)-1], base, nil
+		}
+	}
+
+	return Semver{}, "", fmt.Errorf("not found any %s release before %s", d.pkg, d.rc)
+}
+
+// fetchPrevious source archive of previous release, local one if specified
+func (d *Dist) fetchPrevious() (*diffSide, error) {
+	if d.previousFile != "" {
+		return &diffSide{name: d.previousFile, archive: d.previousFile}, nil
+	}
+
+	v, base, err := d.previousRelease()
+	if err != nil {
+		return nil, err
+	}
+
+	prev := d.Candidate
+	prev.rc = v
+	name := "previous-" + prev.srcArchive()
+	if err := d.download(fmt.Sprintf("%s%s/%s", prev.AreaLink(base), prev.Package(), prev.srcArchive()), name); err != nil {
+		return nil, err
+	}
+
+	return &diffSide{name: v.String(), archive: d.path(name)}, nil
+}
+
+// DiffPrevious diff src archive against previous final release, summary goes
+// into report as a section
+func (d *Dist) DiffPrevious() {
+	if !d.diffPrevious {
+		return
+	}
+
+	item := "previous release diff"
+	prev, err := d.fetchPrevious()
+	if err != nil {
+		d.report.Record(item, false, err)
+		return
+	}
+
+	archive := d.path(d.srcArchive())
+	if _, err := os.Stat(archive); err != nil {
+		d.report.Record(item, false, err)
+		return
+	}
+
+	diff, err := NewReleaseDiff(prev, &diffSide{name: d.rc.String(), archive: archive})
+	if err != nil {
+		d.report.Record(item, false, err)
+		return
+	}
+
+	d.report.Record(fmt.Sprintf("%s since %s", item, prev.name), true, nil)
+	d.report.SetSince(diff.Summarize())
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDist_DiffPrevious(t *testing.T) {
+	previous := tgz(t, []string{"LICENSE", "NOTICE", "go.mod"}, map[string]string{
+		"LICENSE": "Apache License",
+		"NOTICE":  "Copyright 2019-2021",
+		"go.mod":  "module github.com/apache/apisix\n",
+	})
+	current := tgz(t, []string{"LICENSE", "NOTICE", "go.mod", "deps/lua-cjson/cjson.c", "bin/luajit"}, map[string]string{
+		"LICENSE":                "Apache License",
+		"NOTICE":                 "Copyright 2019-2022",
+		"go.mod":                 "module github.com/apache/apisix\n\nrequire github.com/spf13/cobra v1.4.0\n",
+		"deps/lua-cjson/cjson.c": "int main() {}",
+		"bin/luajit":             "\x7fELF\x00",
+	})
+
+	index := func(dirs ...string) string {
+		var body strings.Builder
+		body.WriteString(`<a href="../">..</a>`)
+		for _, dir := range dirs {
+			fmt.Fprintf(&body, `<a href="%s/">%s/</a>`, dir, dir)
+		}
+		return body.String()
+	}
+	pages := map[string]string{
+		"/release/apisix/": index("2.13.0", "apisix-dashboard-2.11.0"),
+		"/archive/apisix/": index("2.12.0", "2.12.1", "2.13.0", "2.14.0"),
+		"/archive/apisix/2.12.1/apache-apisix-2.12.1-src.tgz": string(previous),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer srv.Close()
+
+	rc, _ := ParseSemver("2.13.0-rc1")
+	d := &Dist{
+		Candidate: Candidate{
+			pkg:       pkgAPISix,
+			rc:        rc,
+			pkgPrefix: prefixApache,
+			naming:    apisixNaming,
+		},
+		Linker:       Linker{timeout: 3, workers: 4},
+		repo:         pkgAPISix,
+		dir:          t.TempDir(),
+		diffPrevious: true,
+		releaseArea:  srv.URL + "/release",
+		archiveArea:  srv.URL + "/archive/",
+		report:       &Report{},
+	}
+	if err := os.WriteFile(d.path(d.srcArchive()), current, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d.DiffPrevious()
+	r := d.Report()
+	if !r.Passed() || len(r.Results) != 1 || r.Results[0].Item != "previous release diff since 2.12.1" {
+		t.Fatalf("results = %+v", r.Results)
+	}
+
+	want := &ReleaseSummary{
+		Previous:     "2.12.1",
+		Added:        2,
+		Modified:     2,
+		ThirdParty:   []string{"deps/lua-cjson/cjson.c"},
+		Binaries:     []string{"bin/luajit"},
+		Legal:        []string{"NOTICE"},
+		Dependencies: []DependencyChange{{File: "go.mod", Name: "github.com/spf13/cobra", To: "v1.4.0"}},
+	}
+	if !reflect.DeepEqual(r.Since, want) {
+		t.Errorf("Since = %+v, want %+v", r.Since, want)
+	}
+
+	var buf bytes.Buffer
+	r.Summary(&buf)
+	if !strings.Contains(buf.String(), "  since 2.12.1: 2 added, 0 removed, 2 modified\n    new third-party code: deps/lua-cjson/cjson.c\n") {
+		t.Errorf("Summary() = %s", buf.String())
+	}
+
+	// no release before
+	d.rc, _ = ParseSemver("2.12.0")
+	d.report = &Report{}
+	d.DiffPrevious()
+	if d.report.Passed() {
+		t.Error("DiffPrevious() without previous release expect bad")
+	}
+}
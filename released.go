@@ -0,0 +1,246 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// downloadsLink downloads site which mirrors release area
+const downloadsLink = "https://downloads.apache.org/"
+
+var (
+	downloadsArea  string
+	votedReport    string
+	supportedLines []string
+)
+
+// withSupported supported lines of project definition extended by flag
+func withSupported(lines ...string) []string {
+	return append(lines, supportedLines...)
+}
+
+// votedArtifacts artifacts of voted candidate recorded by earlier report file,
+// the exact candidate if its number specified, otherwise the highest one of version
+func votedArtifacts(filename, project string, voted Semver) ([]Artifact, error) {
+	body, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*Report
+	if err := json.Unmarshal(body, &reports); err != nil {
+		return nil, fmt.Errorf("read report %s: %w", filename, err)
+	}
+
+	var found *Report
+	var highest Semver
+	for _, r := range reports {
+		v, err := ParseSemver(r.Candidate)
+		if err != nil || r.Project != project || v.Version() != voted.Version() {
+			continue
+		}
+		if voted.RC != 0 && v.RC != voted.RC {
+			continue
+		}
+		if found == nil || v.Compare(highest) > 0 {
+			found, highest = r, v
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%s %s not found in report %s", project, voted, filename)
+	}
+	if len(found.Artifacts) == 0 {
+		return nil, fmt.Errorf("no artifact of %s %s recorded in %s", project, found.Candidate, filename)
+	}
+
+	return found.Artifacts, nil
+}
+
+// releaseLink URL of file name of release under area base
+func (d *Dist) releaseLink(base, name string) string {
+	return fmt.Sprintf("%s%s/%s", d.AreaLink(base), d.Package(), name)
+}
+
+// staleReleases releases under release area besides the newest one and the
+// latest of each supported major.minor line, like LTS 2.13
+func staleReleases(versions []Semver, supported []string) []string {
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) > 0
+	})
+
+	keep := map[string]bool{}
+	for _, line := range supported {
+		keep[line] = true
+	}
+
+	var stale []string
+	for i, v := range versions {
+		line := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+		if i > 0 && !keep[line] {
+			stale = append(stale, v.String())
+		}
+		delete(keep, line)
+	}
+	sort.Strings(stale)
+
+	return stale
+}
+
+// validReleaseArea release is under release area, which holds no stale release
+func (d *Dist) validReleaseArea() {
+	body, err := d.Linker.Get(d.AreaLink(d.releaseArea))
+	if err != nil {
+		d.report.Record("release area listing", false, err)
+		return
+	}
+
+	found := false
+	var versions []Semver
+	for _, dir := range listing(body) {
+		if v, ok := d.MatchPackage(dir); ok && v.RC == 0 {
+			versions = append(versions, v)
+			found = found || v.Compare(d.rc) == 0
+		}
+	}
+
+	d.report.Record(fmt.Sprintf("release area has %s", d.Package()), found, nil)
+	d.report.RecordResult(listed("release area only latest releases", staleReleases(versions, d.supported)))
+}
+
+// releaseKeyRing KEYS under release area
+func (d *Dist) releaseKeyRing() (openpgp.EntityList, error) {
+	name := "release-" + keysFilename
+	if err := d.download(d.AreaLink(d.releaseArea)+keysFilename, name); err != nil {
+		return nil, err
+	}
+
+	return readKeyRing(d.path(name))
+}
+
+// validReleased artifact under release area is the voted one, signed by key
+// in release area KEYS
+func (d *Dist) validReleased(voted Artifact, keyring openpgp.EntityList, keysErr error) {
+	item := fmt.Sprintf("release %s identical to voted", voted.Name)
+	signItem := fmt.Sprintf("release %s signed by key in release KEYS", voted.Name)
+	name := "release-" + voted.Name
+	if err := d.download(d.releaseLink(d.releaseArea, voted.Name), name); err != nil {
+		d.report.Record(item, false, err)
+		return
+	}
+
+	var sign *signatureCheck
+	if keysErr == nil {
+		keysErr = d.download(d.releaseLink(d.releaseArea, voted.Name+".asc"), name+".asc")
+	}
+	if keysErr == nil {
+		asc, err := os.Open(d.path(name + ".asc"))
+		if err != nil {
+			keysErr = err
+		} else {
+			defer asc.Close()
+			sign = newSignatureCheck(keyring, asc)
+		}
+	}
+
+	s, err := scanArchive(d.path(name), sign, nil)
+	if err != nil {
+		d.report.Record(item, false, err)
+		return
+	}
+
+	ok := strings.EqualFold(s.artifact.SHA512, voted.SHA512)
+	if !ok {
+		err = fmt.Errorf("SHA-512 %s, voted %s", s.artifact.SHA512, voted.SHA512)
+	}
+	d.report.Record(item, ok, err)
+
+	switch {
+	case keysErr != nil:
+		d.report.Record(signItem, false, keysErr)
+	case sign.err != nil:
+		d.report.Record(signItem, false, sign.err)
+	default:
+		d.report.Record(signItem, true, nil)
+	}
+}
+
+// VerifyReleased verify release after vote: artifacts under release area are
+// identical to voted ones and signed by key in release area KEYS, only the
+// latest releases remain, downloads site and archive have them
+func (d *Dist) VerifyReleased(voted []Artifact) error {
+	d.validReleaseArea()
+
+	keyring, keysErr := d.releaseKeyRing()
+	for _, a := range voted {
+		d.validReleased(a, keyring, keysErr)
+	}
+
+	var checks []linkCheck
+	for _, a := range voted {
+		if d.downloadsArea != "" {
+			checks = append(checks, linkCheck{kind: "downloads", link: d.releaseLink(d.downloadsArea, a.Name)})
+		}
+		if d.archiveArea != "" {
+			checks = append(checks, linkCheck{kind: "archive", link: d.releaseLink(d.archiveArea, a.Name)})
+		}
+	}
+
+	return d.HeadAll(checks, d.report)
+}
+
+// bindReleasedFlags bind released command flags
+func bindReleasedFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&votedReport, "voted", "", "", "Specify JSON report of voted candidate, which records its artifacts")
+	flags.StringSliceVarP(&supportedLines, "supported", "", nil, "Specify supported major.minor lines kept in release area besides the newest, extends project definition")
+	bindReportFlags(flags)
+}
+
+var releasedCmd = &cobra.Command{
+	Use:   "released",
+	Short: "Verify release after vote under release area, downloads site and archive",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d := dist(cmd.Parent().Name())
+		if d == nil {
+			return fmt.Errorf("subcommand released unsupported")
+		}
+		if votedReport == "" {
+			return fmt.Errorf("--voted report required")
+		}
+
+		voted, err := votedArtifacts(votedReport, d.repo, d.rc)
+		if err != nil {
+			return err
+		}
+		d.rc.RC = 0
+
+		if err := d.VerifyReleased(voted); err != nil {
+			return err
+		}
+
+		report := d.Report()
+		report.Summary(os.Stdout)
+		return writeReports(reportFile, []*Report{report})
+	},
+}
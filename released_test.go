@@ -0,0 +1,161 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDist_VerifyReleased(t *testing.T) {
+	// release area with stale releases, downloads site without archive copy
+	root := t.TempDir()
+	name := "apache-apisix-2.13.1-src.tgz"
+	pkg := tgz(t, []string{"LICENSE"}, map[string]string{"LICENSE": "Apache License"})
+	manager := newSigner(t, "Zeping Bai")
+	keys := writeRelease(t, filepath.Join(root, "release/apisix/2.13.1"), name, pkg, manager)
+	for _, dir := range []string{"release/apisix/2.13.0", "release/apisix/2.12.1", "release/apisix/2.12.0", "downloads/apisix/2.13.1"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"release/apisix/KEYS", "downloads/apisix/2.13.1/" + name} {
+		body := pkg
+		if f == "release/apisix/KEYS" {
+			body, _ = os.ReadFile(keys)
+		}
+		if err := os.WriteFile(filepath.Join(root, f), body, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := httptest.NewServer(http.FileServer(http.Dir(root)))
+	defer srv.Close()
+
+	voted := filepath.Join(t.TempDir(), "report.json")
+	report := fmt.Sprintf(`[{"project": "apisix", "candidate": "2.13.1-rc1", "results": [],
+	  "artifacts": [{"name": %q, "size": 1, "sha512": "00"}]},
+	  {"project": "apisix", "candidate": "2.13.1-rc2", "results": [],
+	  "artifacts": [{"name": %q, "size": %d, "sha512": "%x"}]}]`, name, name, len(pkg), sha512.Sum512(pkg))
+	if err := os.WriteFile(voted, []byte(report), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := votedArtifacts(voted, pkgAPISix, Semver{Major: 2, Minor: 14}); err == nil {
+		t.Error("votedArtifacts() of 2.14.0 expect error")
+	}
+	if _, err := votedArtifacts(voted, pkgAPISix, Semver{Major: 2, Minor: 13, Patch: 1, RC: 3}); err == nil {
+		t.Error("votedArtifacts() of 2.13.1-rc3 expect error")
+	}
+	if rc1, err := votedArtifacts(voted, pkgAPISix, Semver{Major: 2, Minor: 13, Patch: 1, RC: 1}); err != nil || rc1[0].Size != 1 {
+		t.Errorf("votedArtifacts() of 2.13.1-rc1 = %+v, %v", rc1, err)
+	}
+	// highest candidate without number specified
+	artifacts, err := votedArtifacts(voted, pkgAPISix, Semver{Major: 2, Minor: 13, Patch: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifacts[0].Size != int64(len(pkg)) {
+		t.Fatalf("votedArtifacts() = %+v, want of 2.13.1-rc2", artifacts)
+	}
+
+	newDist := func() *Dist {
+		return &Dist{
+			Candidate: Candidate{
+				pkg:       pkgAPISix,
+				rc:        Semver{Major: 2, Minor: 13, Patch: 1},
+				pkgPrefix: prefixApache,
+				naming:    apisixNaming,
+			},
+			Linker:        Linker{timeout: 3, workers: 4},
+			repo:          pkgAPISix,
+			dir:           t.TempDir(),
+			releaseArea:   srv.URL + "/release/",
+			archiveArea:   srv.URL + "/archive/",
+			downloadsArea: srv.URL + "/downloads/",
+			supported:     []string{"2.12"},
+			report:        &Report{},
+		}
+	}
+
+	d := newDist()
+	if err := d.VerifyReleased(artifacts); err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{
+		{Item: "release area has 2.13.1", OK: true},
+		{Item: "release area only latest releases", Detail: "2.12.0, 2.13.0"},
+		{Item: "release " + name + " identical to voted", OK: true},
+		{Item: "release " + name + " signed by key in release KEYS", OK: true},
+		{Item: fmt.Sprintf("downloads %s/downloads/apisix/2.13.1/%s validate", srv.URL, name), OK: true},
+		{Item: fmt.Sprintf("archive %s/archive/apisix/2.13.1/%s validate", srv.URL, name)},
+	}
+	results := d.Report().Results
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v, want %d", results, len(want))
+	}
+	for i, res := range results {
+		if res.Item != want[i].Item || res.OK != want[i].OK || (want[i].Detail != "" && res.Detail != want[i].Detail) {
+			t.Errorf("result %d = %+v, want %+v", i, res, want[i])
+		}
+	}
+
+	// release area artifact differs from voted one
+	artifacts[0].SHA512 = fmt.Sprintf("%x", sha512.Sum512([]byte("voted")))
+	d = newDist()
+	if err := d.VerifyReleased(artifacts); err != nil {
+		t.Fatal(err)
+	}
+	if res := d.Report().Results[2]; res.OK {
+		t.Errorf("%s = %v, want bad", res.Item, res.OK)
+	}
+}
+
+func TestStaleReleases(t *testing.T) {
+	versions := func(vs ...string) []Semver {
+		var s []Semver
+		for _, v := range vs {
+			sv, err := ParseSemver(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s = append(s, sv)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name      string
+		versions  []Semver
+		supported []string
+		want      []string
+	}{
+		{name: "newest only", versions: versions("2.12.0", "2.13.1", "2.12.1", "2.13.0"), want: []string{"2.12.0", "2.12.1", "2.13.0"}},
+		{name: "supported line", versions: versions("2.12.0", "2.13.1", "2.12.1", "2.13.0"), supported: []string{"2.12"}, want: []string{"2.12.0", "2.13.0"}},
+		{name: "supported newest line", versions: versions("2.13.1", "2.13.0"), supported: []string{"2.13"}, want: []string{"2.13.0"}},
+		{name: "supported line absent", versions: versions("2.13.1"), supported: []string{"2.10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := staleReleases(tt.versions, tt.supported); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("staleReleases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
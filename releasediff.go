@@ -0,0 +1,419 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var diffFrom, diffTo string
+
+// A ReleaseDiff represents changes between source archives of two releases
+type ReleaseDiff struct {
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	Added        []string           `json:"added,omitempty"`
+	Removed      []string           `json:"removed,omitempty"`
+	Modified     []string           `json:"modified,omitempty"`
+	Legal        []string           `json:"legal,omitempty"` // LICENSE, NOTICE and licenses/ changed
+	Dependencies []DependencyChange `json:"dependencies,omitempty"`
+	FromSigner   string             `json:"fromSigner,omitempty"`
+	ToSigner     string             `json:"toSigner,omitempty"`
+
+	from, to *fileTree
+}
+
+// A diffSide is source archive of one release, with its signature if any
+type diffSide struct {
+	name    string // version or archive file
+	archive string
+	asc     string
+	keyring openpgp.EntityList
+
+	dist *Dist  // candidate fetched from dist, nil for local archive
+	link string // source archive URL, like of svn revision, instead of candidate naming
+}
+
+// isLegal whether file is LICENSE, NOTICE or under licenses directory
+func isLegal(name string) bool {
+	base := strings.ToUpper(path.Base(name))
+	return strings.HasPrefix(base, "LICENSE") || strings.HasPrefix(base, "NOTICE") ||
+		topDir(name) == "licenses"
+}
+
+// keepAll keep content of every file for diffs, large ones excepted
+func keepAll(name string) bool {
+	return true
+}
+
+// NewReleaseDiff compare source archive of to against from
+func NewReleaseDiff(from, to *diffSide) (*ReleaseDiff, error) {
+	fromTree, err := readTree(from.archive, keepAll)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", from.archive, err)
+	}
+	toTree, err := readTree(to.archive, keepAll)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", to.archive, err)
+	}
+
+	files := diffTrees(fromTree, toTree, nil)
+	diff := &ReleaseDiff{
+		From:         from.name,
+		To:           to.name,
+		Added:        files.Added,
+		Removed:      files.Removed,
+		Modified:     files.Modified,
+		Dependencies: diffDependencies(fromTree, toTree),
+		FromSigner:   from.signer(),
+		ToSigner:     to.signer(),
+		from:         fromTree,
+		to:           toTree,
+	}
+	for _, names := range [][]string{files.Added, files.Removed, files.Modified} {
+		for _, name := range names {
+			if isLegal(name) {
+				diff.Legal = append(diff.Legal, name)
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+// signer key ID of signature with primary identity of its key if in keyring,
+// empty if unsigned
+func (s *diffSide) signer() string {
+	if s.asc == "" {
+		return ""
+	}
+
+	id, err := signatureIssuer(s.asc)
+	if err != nil {
+		return fmt.Sprintf("unknown (%s)", err)
+	}
+
+	signer := fmt.Sprintf("%016X", id)
+	for _, key := range s.keyring.KeysById(id) {
+		if ident := key.Entity.PrimaryIdentity(); ident != nil {
+			return signer + " " + ident.Name
+		}
+	}
+	return signer
+}
+
+// signatureIssuer key ID which made armored detached signature
+func signatureIssuer(filename string) (uint64, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	block, err := armor.Decode(f)
+	if err != nil {
+		return 0, err
+	}
+
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return 0, err
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok || sig.IssuerKeyId == nil {
+		return 0, fmt.Errorf("no issuer in signature")
+	}
+
+	return *sig.IssuerKeyId, nil
+}
+
+// SignerChanged whether the two archives signed by different keys, compared
+// by key ID only as identity depends on keyring
+func (r *ReleaseDiff) SignerChanged() bool {
+	return signerKey(r.FromSigner) != signerKey(r.ToSigner)
+}
+
+// signerKey key ID of signer
+func signerKey(signer string) string {
+	if i := strings.IndexByte(signer, ' '); i >= 0 {
+		return signer[:i]
+	}
+	return signer
+}
+
+// Write plain text summary of changes, then unified diffs of modified files
+func (r *ReleaseDiff) Write(w io.Writer) {
+	fmt.Fprintf(w, "diff %s %s\n", r.From, r.To)
+	section := func(title string, names []string) {
+		fmt.Fprintf(w, "%s: %d\n", title, len(names))
+		for _, name := range names {
+			fmt.Fprintf(w, "  %s\n", name)
+		}
+	}
+	section("added", r.Added)
+	section("removed", r.Removed)
+	section("modified", r.Modified)
+	section("LICENSE/NOTICE changed", r.Legal)
+
+	fmt.Fprintf(w, "dependencies changed: %d\n", len(r.Dependencies))
+	for _, c := range r.Dependencies {
+		fmt.Fprintf(w, "  %s\n", c)
+	}
+
+	if r.SignerChanged() {
+		fmt.Fprintf(w, "signing key changed: %s -> %s\n", orNone(r.FromSigner), orNone(r.ToSigner))
+	} else {
+		fmt.Fprintf(w, "signing key unchanged: %s\n", orNone(r.ToSigner))
+	}
+
+	for _, name := range r.Modified {
+		from, to := r.from.files[name], r.to.files[name]
+		if from.link != "" || to.link != "" {
+			fmt.Fprintf(w, "Symbolic link %s: %s -> %s\n", name, from.link, to.link)
+			continue
+		}
+		if from.body == nil || to.body == nil {
+			fmt.Fprintf(w, "Files a/%s and b/%s differ\n", name, name)
+			continue
+		}
+		writeUnified(w, name, from.body, to.body)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+
+	return s
+}
+
+// resolveSide source archive of value: an existing local archive with
+// sibling .asc if any, a source archive URL, otherwise candidate version of
+// project under dist, which downloads into directory of side within workspace
+// once fetched
+func resolveSide(project, value, side string) (*diffSide, error) {
+	if value == "" {
+		return nil, fmt.Errorf("both --from and --to required")
+	}
+	if strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://") {
+		return resolveLink(project, value, side)
+	}
+
+	if isArchive(value) {
+		if _, err := os.Stat(value); err == nil {
+			s := &diffSide{name: value, archive: value}
+			if _, err := os.Stat(value + ".asc"); err == nil {
+				s.asc = value + ".asc"
+			}
+			if keysFile != "" {
+				if s.keyring, err = readKeyRing(keysFile); err != nil {
+					return nil, err
+				}
+			}
+			return s, nil
+		}
+	}
+
+	if project == "" {
+		return nil, fmt.Errorf("project required to fetch %s", value)
+	}
+	if err := openWorkspace(); err != nil {
+		return nil, err
+	}
+
+	task := Task{Project: project, Candidate: value}
+	d, err := task.Dist(workspace)
+	if err != nil {
+		return nil, err
+	}
+	d.dir = filepath.Join(workspace.Dir(), side, d.Package())
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &diffSide{name: d.rc.String(), archive: d.path(d.srcArchive()), dist: d}, nil
+}
+
+// resolveLink source archive of URL, like one of candidate at svn revision
+// https://dist.apache.org/repos/dist/dev/apisix/2.13.0/apache-apisix-2.13.0-src.tgz?p=53000,
+// signers named by KEYS of project if specified
+func resolveLink(project, link, side string) (*diffSide, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	name := path.Base(u.Path)
+	if !isArchive(name) {
+		return nil, fmt.Errorf("%s not a source archive", link)
+	}
+
+	if err := openWorkspace(); err != nil {
+		return nil, err
+	}
+
+	d := &Dist{Linker: newLinker(), ws: workspace, keys: keysFile}
+	if project != "" {
+		if d = dist(project); d == nil {
+			return nil, fmt.Errorf("project %s unsupported", project)
+		}
+		d.ws = workspace
+	}
+	d.dir = filepath.Join(workspace.Dir(), side)
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &diffSide{name: link, archive: d.path(name), dist: d, link: link}, nil
+}
+
+// source URL which side downloads from, empty for local archive
+func (s *diffSide) source() string {
+	switch {
+	case s.link != "":
+		return s.link
+	case s.dist != nil:
+		return s.dist.SrcLink()
+	default:
+		return ""
+	}
+}
+
+// fetch download source archive of candidate or URL with its signature if
+// any, nothing to do for local archive
+func (s *diffSide) fetch() error {
+	d := s.dist
+	if d == nil {
+		return nil
+	}
+
+	if s.link != "" {
+		return s.fetchLink()
+	}
+
+	if err := d.fetchSrc(); err != nil {
+		return err
+	}
+	if err := d.fetchSrcAsc(); err == nil {
+		s.asc = d.path(d.srcAsc())
+	}
+	// signer stays a bare key ID without KEYS
+	s.keyring, _ = d.projectKeyRing()
+	return nil
+}
+
+// fetchLink download source archive of URL, signature from the same URL
+// with .asc appended to its path
+func (s *diffSide) fetchLink() error {
+	d := s.dist
+	name := filepath.Base(s.archive)
+	if err := d.download(s.link, name); err != nil {
+		return err
+	}
+
+	u, _ := url.Parse(s.link)
+	u.Path += ".asc"
+	if err := d.download(u.String(), name+".asc"); err == nil {
+		s.asc = s.archive + ".asc"
+	}
+
+	if d.repo != "" {
+		s.keyring, _ = d.projectKeyRing()
+	} else if d.keys != "" {
+		s.keyring, _ = readKeyRing(d.keys)
+	}
+	return nil
+}
+
+// resolveSides source archives of --from and --to, which must differ,
+// fetched from dist if candidates or URLs; candidates of the same version
+// share one dist URL, compare URLs of svn revisions for them
+func resolveSides(project string) (*diffSide, *diffSide, error) {
+	from, err := resolveSide(project, diffFrom, "from")
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := resolveSide(project, diffTo, "to")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if link := from.source(); link != "" && link == to.source() {
+		return nil, nil, fmt.Errorf("%s and %s resolve to the same %s, compare URLs of svn revisions or local archives instead", from.name, to.name, link)
+	}
+	if from.archive == to.archive {
+		return nil, nil, fmt.Errorf("%s and %s are the same file", from.name, to.name)
+	}
+
+	for _, s := range []*diffSide{from, to} {
+		if err := s.fetch(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return from, to, nil
+}
+
+// bindDiffFlags bind diff command flags
+func bindDiffFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&diffFrom, "from", "", "", "Specify candidate version, source archive URL or local source archive to compare from")
+	flags.StringVarP(&diffTo, "to", "", "", "Specify candidate version, source archive URL or local source archive to compare to")
+	flags.StringVarP(&keysFile, "keys", "k", "", "Specify project KEYS file names signers, defaults to KEYS under dist")
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [project]",
+	Short: "Show changes between two release candidates, source archive URLs or local source archives",
+	Long: `Show changes between two release candidates, source archive URLs or local source archives.
+Candidates of the same version, like 2.13.0-rc1 and 2.13.0-rc2, share one dist URL,
+compare them by URLs of svn revisions, like ...-src.tgz?p=53000, or local archives.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: projectNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project := ""
+		if len(args) > 0 {
+			project = args[0]
+		}
+
+		from, to, err := resolveSides(project)
+		if err != nil {
+			return err
+		}
+
+		diff, err := NewReleaseDiff(from, to)
+		if err != nil {
+			return err
+		}
+
+		diff.Write(os.Stdout)
+		return nil
+	},
+}
+
+func init() {
+	bindDiffFlags(diffCmd.Flags())
+}
@@ -0,0 +1,271 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+const rockspec = `package = "apisix"
+dependencies = {
+    "lua-resty-etcd = 1.6.0",
+    "lua-resty-jwt = 0.2.0",
+}
+`
+
+// writeArchive write tgz of files signed by signer into dir, returns its filename
+func writeArchive(t *testing.T, dir, name string, files map[string]string, signer *openpgp.Entity) string {
+	var names []string
+	for n := range files {
+		names = append(names, n)
+	}
+	writeRelease(t, dir, name, tgz(t, names, files), signer)
+
+	return filepath.Join(dir, name)
+}
+
+func TestReleaseDiff(t *testing.T) {
+	top := "apache-apisix-2.13.0-src/"
+	from := map[string]string{
+		top + "LICENSE":                  "Apache License",
+		top + "NOTICE":                   "Apache APISIX\nCopyright 2019-2022",
+		top + "apisix/init.lua":          "local a = 1\nlocal b = 2\nreturn a + b\n",
+		top + "go.mod":                   "module github.com/apache/apisix\n\nrequire (\n\tgithub.com/spf13/cobra v1.3.0 // indirect\n\tgolang.org/x/net v0.1.0\n)\n",
+		top + "apisix-master-0.rockspec": rockspec,
+		top + "docs/old.md":              "# Old",
+	}
+	to := map[string]string{
+		top + "LICENSE":                  "Apache License",
+		top + "NOTICE":                   "Apache APISIX\nCopyright 2019-2023",
+		top + "apisix/init.lua":          "local a = 1\nlocal b = 3\nreturn a + b\n",
+		top + "go.mod":                   "module github.com/apache/apisix\n\nrequire github.com/spf13/cobra v1.4.0\n",
+		top + "apisix-master-0.rockspec": strings.Replace(rockspec, "0.2.0", "0.2.1", 1),
+		top + "web/package.json":         `{"dependencies": {"react": "^17.0.2"}, "devDependencies": {"jest": "27.0.0"}}`,
+		top + "bin/tool":                 "\x7fELF\x00\x01",
+	}
+
+	dir := t.TempDir()
+	manager, other := newSigner(t, "Zeping Bai"), newSigner(t, "Someone Else")
+	fromFile := writeArchive(t, filepath.Join(dir, "rc1"), "apache-apisix-2.13.0-src.tgz", from, manager)
+	toFile := writeArchive(t, filepath.Join(dir, "rc2"), "apache-apisix-2.13.0-src.tgz", to, other)
+
+	keysFile = writeKeys(t, manager)
+	defer func() { keysFile = "" }()
+
+	diffFrom, diffTo = fromFile, toFile
+	defer func() { diffFrom, diffTo = "", "" }()
+	fromSide, toSide, err := resolveSides("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := NewReleaseDiff(fromSide, toSide)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"bin/tool", "web/package.json"}; !reflect.DeepEqual(diff.Added, want) {
+		t.Errorf("Added = %v, want %v", diff.Added, want)
+	}
+	if want := []string{"docs/old.md"}; !reflect.DeepEqual(diff.Removed, want) {
+		t.Errorf("Removed = %v, want %v", diff.Removed, want)
+	}
+	if want := []string{"NOTICE", "apisix-master-0.rockspec", "apisix/init.lua", "go.mod"}; !reflect.DeepEqual(diff.Modified, want) {
+		t.Errorf("Modified = %v, want %v", diff.Modified, want)
+	}
+	if want := []string{"NOTICE"}; !reflect.DeepEqual(diff.Legal, want) {
+		t.Errorf("Legal = %v, want %v", diff.Legal, want)
+	}
+
+	wantDeps := []DependencyChange{
+		{File: "apisix-master-0.rockspec", Name: "lua-resty-jwt", From: "= 0.2.0", To: "= 0.2.1"},
+		{File: "go.mod", Name: "github.com/spf13/cobra", From: "v1.3.0", To: "v1.4.0"},
+		{File: "go.mod", Name: "golang.org/x/net", From: "v0.1.0"},
+		{File: "web/package.json", Name: "jest", To: "27.0.0"},
+		{File: "web/package.json", Name: "react", To: "^17.0.2"},
+	}
+	if !reflect.DeepEqual(diff.Dependencies, wantDeps) {
+		t.Errorf("Dependencies = %+v, want %+v", diff.Dependencies, wantDeps)
+	}
+
+	if !diff.SignerChanged() || !strings.HasSuffix(diff.FromSigner, " Zeping Bai <release@apache.org>") || strings.Contains(diff.ToSigner, " ") {
+		t.Errorf("signers = %q -> %q", diff.FromSigner, diff.ToSigner)
+	}
+
+	var buf bytes.Buffer
+	diff.Write(&buf)
+	for _, want := range []string{
+		"added: 2\n  bin/tool\n",
+		"LICENSE/NOTICE changed: 1\n  NOTICE\n",
+		"  go.mod github.com/spf13/cobra v1.3.0 -> v1.4.0\n",
+		"  web/package.json + react ^17.0.2\n",
+		"signing key changed: ",
+		"--- a/apisix/init.lua\n+++ b/apisix/init.lua\n@@ -1,3 +1,3 @@\n local a = 1\n-local b = 2\n+local b = 3\n return a + b\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Write() missing %q in:\n%s", want, buf.String())
+		}
+	}
+
+	// fetching a candidate needs project
+	if _, err := resolveSide("", "2.13.0-rc1", "from"); err == nil {
+		t.Error("resolveSide() without project expect error")
+	}
+	if _, err := os.Stat(fromFile + ".asc"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveSides(t *testing.T) {
+	files := map[string][]byte{
+		baseLink + "2.12.0/apache-apisix-2.12.0-src.tgz": tgz(t, []string{"README.md"}, map[string]string{"README.md": "# 2.12.0"}),
+		baseLink + "2.13.0/apache-apisix-2.13.0-src.tgz": tgz(t, []string{"README.md"}, map[string]string{"README.md": "# 2.13.0"}),
+		// candidates of svn revisions
+		baseLink + "2.13.0/apache-apisix-2.13.0-src.tgz?p=100": tgz(t, []string{"README.md"}, map[string]string{"README.md": "# 2.13.0-rc1"}),
+		baseLink + "2.13.0/apache-apisix-2.13.0-src.tgz?p=200": tgz(t, []string{"README.md", "NOTICE"}, map[string]string{"README.md": "# 2.13.0-rc2", "NOTICE": "Apache APISIX"}),
+	}
+	var requests []string
+	saved := transport
+	transport = fakeTransport(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.String())
+		body, ok := files[req.URL.String()]
+		if !ok {
+			return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(bytes.NewReader(body)), ContentLength: int64(len(body)), Request: req}, nil
+	})
+	workdir = t.TempDir()
+	defer func() {
+		transport, workdir = saved, ""
+		diffFrom, diffTo = "", ""
+		closeWorkspace()
+	}()
+
+	diffFrom, diffTo = "2.12.0", "2.13.0"
+	from, to, err := resolveSides(pkgAPISix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from.archive == to.archive {
+		t.Fatalf("both sides download into %s", from.archive)
+	}
+	diff, err := NewReleaseDiff(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"README.md"}; !reflect.DeepEqual(diff.Modified, want) {
+		t.Errorf("Modified = %v, want %v", diff.Modified, want)
+	}
+
+	// candidates of apisix naming share one dist directory
+	requests = nil
+	diffFrom, diffTo = "2.13.0-rc1", "2.13.0-rc2"
+	if _, _, err := resolveSides(pkgAPISix); err == nil || !strings.Contains(err.Error(), "resolve to the same") {
+		t.Errorf("resolveSides() error = %v, want same URL error", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("requests = %v, want none", requests)
+	}
+
+	// the same candidates by URLs of svn revisions
+	diffFrom = baseLink + "2.13.0/apache-apisix-2.13.0-src.tgz?p=100"
+	diffTo = baseLink + "2.13.0/apache-apisix-2.13.0-src.tgz?p=200"
+	from, to, err = resolveSides(pkgAPISix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff, err = NewReleaseDiff(from, to); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(diff.Added, []string{"NOTICE"}) || !reflect.DeepEqual(diff.Modified, []string{"README.md"}) {
+		t.Errorf("Added = %v, Modified = %v", diff.Added, diff.Modified)
+	}
+
+	diffTo = diffFrom
+	if _, _, err := resolveSides(""); err == nil || !strings.Contains(err.Error(), "resolve to the same") {
+		t.Errorf("resolveSides() error = %v, want same URL error", err)
+	}
+}
+
+func TestReleaseDiff_SignerChanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     bool
+	}{
+		{name: "same key", from: "0123456789ABCDEF Zeping Bai <release@apache.org>", to: "0123456789ABCDEF Zeping Bai <release@apache.org>"},
+		{name: "same key not in keyring", from: "0123456789ABCDEF Zeping Bai <release@apache.org>", to: "0123456789ABCDEF"},
+		{name: "different key", from: "0123456789ABCDEF Zeping Bai <release@apache.org>", to: "FEDCBA9876543210 Zeping Bai <release@apache.org>", want: true},
+		{name: "unsigned", from: "0123456789ABCDEF", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ReleaseDiff{FromSigner: tt.from, ToSigner: tt.to}
+			if got := r.SignerChanged(); got != tt.want {
+				t.Errorf("SignerChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteUnified(t *testing.T) {
+	var from, to []string
+	for i := 1; i <= 20; i++ {
+		from = append(from, strings.Repeat("x", i))
+	}
+	to = append(append([]string{"head"}, from[:9]...), from[10:]...)
+	to = append(to, "tail")
+
+	var buf bytes.Buffer
+	writeUnified(&buf, "a.txt", []byte(strings.Join(from, "\n")+"\n"), []byte(strings.Join(to, "\n")+"\n"))
+	want := `--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,4 @@
++head
+ x
+ xx
+ xxx
+@@ -7,7 +8,6 @@
+ xxxxxxx
+ xxxxxxxx
+ xxxxxxxxx
+-xxxxxxxxxx
+ xxxxxxxxxxx
+ xxxxxxxxxxxx
+ xxxxxxxxxxxxx
+@@ -18,3 +18,4 @@
+ xxxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxxxx
++tail
+`
+	if buf.String() != want {
+		t.Errorf("writeUnified() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeUnified(&buf, "same.txt", []byte("a\n"), []byte("a\n"))
+	if buf.Len() != 0 {
+		t.Errorf("writeUnified() identical = %q", buf.String())
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+var reportFile string
+
+// A Result represents outcome of one verification item
+type Result struct {
+	Item   string `json:"item"`
+	OK     bool   `json:"ok"`
+	Kind   string `json:"kind,omitempty"` // missing or unreachable for request failure
+	Detail string `json:"detail,omitempty"`
+}
+
+// A Report collects verification results of a candidate
+type Report struct {
+	Project   string     `json:"project"`
+	Candidate string     `json:"candidate"`
+	Results   []Result   `json:"results"`
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// Since changes since previous release
+	Since *ReleaseSummary `json:"since,omitempty"`
+
+	mu sync.Mutex
+}
+
+// Record log item outcome and collect it, nil report only logs
+func (r *Report) Record(item string, ok bool, err error) {
+	res := Result{Item: item, OK: ok}
+	if err != nil {
+		res.OK = false
+		res.Kind = errorKind(err)
+		res.Detail = err.Error()
+	}
+
+	r.RecordResult(res)
+}
+
+// RecordResult log result and collect it, like results of archive checks
+func (r *Report) RecordResult(res Result) {
+	switch {
+	case res.OK:
+		log.Printf("%s ok ✅\n", res.Item)
+	case res.Detail != "":
+		log.Printf("%s bad ❌ %s\n", res.Item, res.Detail)
+	default:
+		log.Printf("%s bad ❌\n", res.Item)
+	}
+
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.Results = append(r.Results, res)
+	r.mu.Unlock()
+}
+
+// AddArtifact collect digests of verified artifact, nil report ignores
+func (r *Report) AddArtifact(a Artifact) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.Artifacts = append(r.Artifacts, a)
+	r.mu.Unlock()
+}
+
+// SetSince set summary of changes since previous release, nil report ignores
+func (r *Report) SetSince(s *ReleaseSummary) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.Since = s
+	r.mu.Unlock()
+}
+
+// Passed whether all items are ok
+func (r *Report) Passed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, res := range r.Results {
+		if !res.OK {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Summary write plain text summary, bad items with detail
+func (r *Report) Summary(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ok := 0
+	for _, res := range r.Results {
+		if res.OK {
+			ok++
+		}
+	}
+
+	fmt.Fprintf(w, "%s %s: %d ok, %d bad\n", r.Project, r.Candidate, ok, len(r.Results)-ok)
+	for _, res := range r.Results {
+		if res.OK {
+			continue
+		}
+		if res.Kind != "" {
+			fmt.Fprintf(w, "  ❌ [%s] %s: %s\n", res.Kind, res.Item, res.Detail)
+		} else if res.Detail != "" {
+			fmt.Fprintf(w, "  ❌ %s: %s\n", res.Item, res.Detail)
+		} else {
+			fmt.Fprintf(w, "  ❌ %s\n", res.Item)
+		}
+	}
+
+	if r.Since != nil {
+		r.Since.Write(w)
+	}
+}
+
+// writeReports save reports as JSON file
+func writeReports(filename string, reports []*Report) error {
+	if filename == "" {
+		return nil
+	}
+
+	body, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, body, 0644)
+}
+
+// bindReportFlags bind report file flag
+func bindReportFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&reportFile, "report", "r", "", "Specify file to save combined JSON report")
+}
+
+// summarize print summary of reports and save them into report file,
+// error if any candidate not passed
+func summarize(reports []*Report) error {
+	passed := true
+	for _, r := range reports {
+		r.Summary(os.Stdout)
+		passed = passed && r.Passed()
+	}
+
+	if err := writeReports(reportFile, reports); err != nil {
+		return err
+	}
+
+	if !passed {
+		return fmt.Errorf("not all candidates passed")
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	maxBackoff = time.Minute
+
+	kindMissing     = "missing"
+	kindUnreachable = "unreachable"
+)
+
+// A StatusError represents non-expected response status
+type StatusError struct {
+	Link   string
+	Code   int
+	Status string
+	Header http.Header
+}
+
+func newStatusError(link string, res *http.Response) *StatusError {
+	return &StatusError{
+		Link:   link,
+		Code:   res.StatusCode,
+		Status: res.Status,
+		Header: res.Header,
+	}
+}
+
+func (e *StatusError) Error() string {
+	switch {
+	case e.missing():
+		return fmt.Sprintf("resource missing %s", e.Status)
+	case e.transient():
+		return fmt.Sprintf("could not reach %s", e.Status)
+	}
+
+	return fmt.Sprintf("non-expected response status %s", e.Status)
+}
+
+func (e *StatusError) missing() bool {
+	return e.Code == http.StatusNotFound || e.Code == http.StatusGone
+}
+
+// rateLimited github rate limit exceeded
+func (e *StatusError) rateLimited() bool {
+	return e.Code == http.StatusForbidden && e.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+func (e *StatusError) transient() bool {
+	switch e.Code {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return e.rateLimited()
+}
+
+// retryAfter server suggested delay from Retry-After or github rate limit reset
+func (e *StatusError) retryAfter() (time.Duration, bool) {
+	if v := e.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return time.Until(t), true
+		}
+	}
+
+	if e.rateLimited() {
+		if reset, err := strconv.ParseInt(e.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Until(time.Unix(reset, 0)), true
+		}
+	}
+
+	return 0, false
+}
+
+// An UnreachableError represents request failure before or while receiving response
+type UnreachableError struct {
+	Link string
+	Err  error
+}
+
+func (e *UnreachableError) Error() string {
+	return fmt.Sprintf("could not reach %s: %s", e.Link, e.Err)
+}
+
+func (e *UnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// errorKind classify error as resource missing or could not reach
+func errorKind(err error) string {
+	var se *StatusError
+	if errors.As(err, &se) {
+		switch {
+		case se.missing():
+			return kindMissing
+		case se.transient():
+			return kindUnreachable
+		}
+		return ""
+	}
+
+	var ue *UnreachableError
+	if errors.As(err, &ue) {
+		return kindUnreachable
+	}
+
+	return ""
+}
+
+// isUnreachable whether request failed without any response
+func isUnreachable(err error) bool {
+	var ue *UnreachableError
+	return errors.As(err, &ue)
+}
+
+func transient(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.transient()
+	}
+
+	// replaying never gets a different answer
+	return isUnreachable(err) && !errors.Is(err, errNotRecorded)
+}
+
+// A Retrier retries transient failures with exponential backoff and jitter
+type Retrier struct {
+	Retries uint          // retry count after first attempt
+	Backoff time.Duration // first backoff, doubled each retry
+}
+
+// delay wait duration before retry attempt n, start from zero
+func (r *Retrier) delay(err error, n uint) time.Duration {
+	var se *StatusError
+	if errors.As(err, &se) {
+		if d, ok := se.retryAfter(); ok {
+			if d < 0 {
+				d = 0
+			}
+			if d > maxBackoff {
+				d = maxBackoff
+			}
+			return d
+		}
+	}
+
+	if r.Backoff <= 0 {
+		return 0
+	}
+	d := r.Backoff << n
+	if d>>n != r.Backoff || d > maxBackoff {
+		d = maxBackoff
+	}
+
+	// equal jitter, wait within the upper half of backoff
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// Do call fn until it succeeds, fails permanently, retries exhausted or ctx done
+func (r *Retrier) Do(ctx context.Context, fn func() error) error {
+	var err error
+	for n := uint(0); ; n++ {
+		if err = fn(); err == nil || !transient(err) || n >= r.Retries {
+			return err
+		}
+
+		d := r.delay(err, n)
+		log.Printf("retry %d/%d after %s: %s\n", n+1, r.Retries, d.Round(time.Millisecond), err)
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetrier_Head(t *testing.T) {
+	attempts := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts[r.URL.Path]++
+		switch r.URL.Path {
+		case "/flaky":
+			if attempts[r.URL.Path] < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+		case "/rate-limited":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	l := &Linker{timeout: 3, retrier: Retrier{Retries: 3, Backoff: time.Millisecond}}
+
+	tests := []struct {
+		path     string
+		ok       bool
+		kind     string
+		attempts int
+	}{
+		{path: "/flaky", ok: true, attempts: 3},
+		{path: "/rate-limited", kind: kindUnreachable, attempts: 4},
+		{path: "/missing", kind: kindMissing, attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ok, err := l.Head(srv.URL + tt.path)
+			if ok != tt.ok {
+				t.Errorf("Head() = %v, want %v, error %v", ok, tt.ok, err)
+			}
+			if kind := errorKind(err); kind != tt.kind {
+				t.Errorf("errorKind() = %q, want %q", kind, tt.kind)
+			}
+			if attempts[tt.path] != tt.attempts {
+				t.Errorf("attempts = %d, want %d", attempts[tt.path], tt.attempts)
+			}
+		})
+	}
+
+	srv.Close()
+	if _, err := l.Head(srv.URL); errorKind(err) != kindUnreachable || !isUnreachable(err) {
+		t.Errorf("closed server expect unreachable, got %v", err)
+	}
+}
+
+func TestRetrier_delay(t *testing.T) {
+	err := &StatusError{Code: http.StatusBadGateway}
+	tests := []struct {
+		name     string
+		backoff  time.Duration
+		n        uint
+		min, max time.Duration
+	}{
+		{name: "zero backoff no wait", backoff: 0, n: 3},
+		{name: "first attempt", backoff: time.Second, min: time.Second / 2, max: time.Second},
+		{name: "doubled", backoff: time.Second, n: 2, min: 2 * time.Second, max: 4 * time.Second},
+		{name: "clamped", backoff: time.Second, n: 10, min: maxBackoff / 2, max: maxBackoff},
+		{name: "overflow clamped", backoff: time.Second, n: 62, min: maxBackoff / 2, max: maxBackoff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Retrier{Backoff: tt.backoff}
+			if d := r.delay(err, tt.n); d < tt.min || d > tt.max {
+				t.Errorf("delay() = %s, want within [%s, %s]", d, tt.min, tt.max)
+			}
+		})
+	}
+}
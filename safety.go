@@ -0,0 +1,187 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	checkSafety = "safety"
+
+	// maxListed entries listed in detail of a result
+	maxListed = 5
+)
+
+// entryName normalize entry name: slash separated, without leading ./ and trailing /
+func entryName(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	for strings.HasPrefix(name, "./") {
+		name = name[2:]
+	}
+
+	return strings.TrimSuffix(name, "/")
+}
+
+// topDir first component of normalized entry name
+func topDir(name string) string {
+	if i := strings.Index(name, "/"); i >= 0 {
+		return name[:i]
+	}
+
+	return name
+}
+
+// isAbsName whether name is absolute on unix or windows
+func isAbsName(name string) bool {
+	return strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':')
+}
+
+// A link entry resolves after all entries seen, top directory known by then
+type link struct {
+	name   string
+	target string // resolved from archive root
+}
+
+// safetyCheck reports entries dangerous to extract
+type safetyCheck struct {
+	absolute   []string
+	parent     []string
+	special    []string
+	modes      []string
+	duplicates []string
+	links      []link
+
+	seen map[string]bool
+	tops map[string]bool
+}
+
+func newSafetyCheck() *safetyCheck {
+	return &safetyCheck{seen: map[string]bool{}, tops: map[string]bool{}}
+}
+
+func (c *safetyCheck) Begin(archive string) error {
+	return nil
+}
+
+func (c *safetyCheck) Entry(hdr *tar.Header, r io.Reader) error {
+	if hdr.Typeflag == tar.TypeXGlobalHeader {
+		return nil
+	}
+
+	raw := strings.ReplaceAll(hdr.Name, `\`, "/")
+	name := entryName(hdr.Name)
+	if name == "" || name == "." {
+		return nil
+	}
+
+	if isAbsName(raw) {
+		c.absolute = append(c.absolute, hdr.Name)
+	}
+	for _, part := range strings.Split(raw, "/") {
+		if part == ".." {
+			c.parent = append(c.parent, hdr.Name)
+			break
+		}
+	}
+
+	if c.seen[name] {
+		c.duplicates = append(c.duplicates, hdr.Name)
+	}
+	c.seen[name] = true
+	c.tops[topDir(name)] = true
+
+	switch hdr.Typeflag {
+	case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+		c.special = append(c.special, hdr.Name)
+	case tar.TypeSymlink:
+		target := hdr.Linkname
+		if !isAbsName(target) {
+			target = path.Join(path.Dir(name), target)
+		}
+		c.links = append(c.links, link{name: hdr.Name, target: target})
+	case tar.TypeLink:
+		c.links = append(c.links, link{name: hdr.Name, target: hdr.Linkname})
+	}
+
+	if hdr.Typeflag != tar.TypeSymlink && hdr.Mode&(04000|02000|0002) != 0 {
+		c.modes = append(c.modes, fmt.Sprintf("%s %04o", hdr.Name, hdr.Mode&07777))
+	}
+
+	return nil
+}
+
+// escaping links resolve outside the top directory, or the root if there are many
+func (c *safetyCheck) escaping() []string {
+	top := ""
+	if len(c.tops) == 1 {
+		for t := range c.tops {
+			top = t
+		}
+	}
+
+	var names []string
+	for _, l := range c.links {
+		target := path.Clean(strings.ReplaceAll(l.target, `\`, "/"))
+		escaped := isAbsName(target) || target == ".." || strings.HasPrefix(target, "../")
+		if !escaped && top != "" && topDir(entryName(target)) != top {
+			escaped = true
+		}
+		if escaped {
+			names = append(names, fmt.Sprintf("%s -> %s", l.name, l.target))
+		}
+	}
+
+	return names
+}
+
+func (c *safetyCheck) End() []Result {
+	return []Result{
+		listed("archive absolute paths", c.absolute),
+		listed("archive parent components", c.parent),
+		listed("archive escaping links", c.escaping()),
+		listed("archive special files", c.special),
+		listed("archive setuid or world-writable modes", c.modes),
+		listed("archive duplicate entries", c.duplicates),
+	}
+}
+
+// listed result ok if no names, otherwise detail lists the first ones
+func listed(item string, names []string) Result {
+	if len(names) == 0 {
+		return Result{Item: item, OK: true}
+	}
+
+	return Result{Item: item, Detail: listDetail(names)}
+}
+
+// listDetail the first names, with count of the rest
+func listDetail(names []string) string {
+	if len(names) > maxListed {
+		return fmt.Sprintf("%s and %d more", strings.Join(names[:maxListed], ", "), len(names)-maxListed)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func init() {
+	RegisterArchiveCheck(checkSafety, func(d *Dist) ArchiveCheck {
+		return newSafetyCheck()
+	})
+}
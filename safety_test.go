@@ -0,0 +1,100 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSafetyCheck(t *testing.T) {
+	reg := func(name string) *tar.Header {
+		return &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg}
+	}
+	dir := &tar.Header{Name: "apisix-2.11.0/", Mode: 0755, Typeflag: tar.TypeDir}
+
+	tests := []struct {
+		name string
+		hdrs []*tar.Header
+		bad  string // item expected bad, others ok
+	}{
+		{
+			name: "safe",
+			hdrs: []*tar.Header{dir, reg("apisix-2.11.0/LICENSE"),
+				{Name: "apisix-2.11.0/bin/apisix", Linkname: "../apisix/cli.lua", Typeflag: tar.TypeSymlink, Mode: 0777}},
+		},
+		{name: "absolute", hdrs: []*tar.Header{reg("/etc/passwd")}, bad: "archive absolute paths"},
+		{name: "parent", hdrs: []*tar.Header{dir, reg("apisix-2.11.0/../../.bashrc")}, bad: "archive parent components"},
+		{
+			name: "symlink",
+			hdrs: []*tar.Header{dir, {Name: "apisix-2.11.0/conf", Linkname: "../../etc", Typeflag: tar.TypeSymlink}},
+			bad:  "archive escaping links",
+		},
+		{
+			name: "symlink out of top",
+			hdrs: []*tar.Header{dir, {Name: "apisix-2.11.0/conf", Linkname: "../other", Typeflag: tar.TypeSymlink}},
+			bad:  "archive escaping links",
+		},
+		{
+			name: "hardlink",
+			hdrs: []*tar.Header{dir, {Name: "apisix-2.11.0/passwd", Linkname: "/etc/passwd", Typeflag: tar.TypeLink}},
+			bad:  "archive escaping links",
+		},
+		{name: "fifo", hdrs: []*tar.Header{dir, {Name: "apisix-2.11.0/pipe", Typeflag: tar.TypeFifo, Mode: 0644}}, bad: "archive special files"},
+		{name: "device", hdrs: []*tar.Header{dir, {Name: "apisix-2.11.0/sda", Typeflag: tar.TypeBlock, Mode: 0644}}, bad: "archive special files"},
+		{name: "setuid", hdrs: []*tar.Header{dir, {Name: "apisix-2.11.0/run", Typeflag: tar.TypeReg, Mode: 04755}}, bad: "archive setuid or world-writable modes"},
+		{name: "world-writable", hdrs: []*tar.Header{dir, {Name: "apisix-2.11.0/tmp", Typeflag: tar.TypeDir, Mode: 0777}}, bad: "archive setuid or world-writable modes"},
+		{name: "duplicate", hdrs: []*tar.Header{dir, reg("apisix-2.11.0/LICENSE"), reg("./apisix-2.11.0/LICENSE")}, bad: "archive duplicate entries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "pkg-src.tgz")
+			if err := os.WriteFile(filename, tgzHeaders(t, tt.hdrs), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			c := newSafetyCheck()
+			s, err := scanArchive(filename, nil, []entryVisitor{c.Entry})
+			if err != nil || s.entriesErr != nil {
+				t.Fatalf("scanArchive() error = %v %v", err, s.entriesErr)
+			}
+
+			for _, res := range c.End() {
+				if want := res.Item != tt.bad; res.OK != want {
+					t.Errorf("%s = %v %s, want %v", res.Item, res.OK, res.Detail, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDist_CheckExtrasCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	pkg := tgz(t, []string{"LICENSE", "NOTICE"}, map[string]string{"LICENSE": "Apache License", "NOTICE": "Apache APISIX"})
+	name := "apache-apisix-dashboard-2.11.0-src.tgz"
+	if err := os.WriteFile(filepath.Join(dir, name), pkg[:len(pkg)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := localDist(filepath.Join(dir, name))
+	if ok, err := d.CheckExtras(); ok || err == nil {
+		t.Errorf("CheckExtras() = %v, %v, want corrupted", ok, err)
+	}
+	if r := d.Report(); r.Passed() || r.Results[0].Item != "archive corrupted" {
+		t.Errorf("report results = %+v, want archive corrupted first", r.Results)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	semverCore = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?$`)
+	semverRC   = regexp.MustCompile(`(?:^|[.-])rc(\d+)$`)
+)
+
+// A Semver represents a release version with an optional candidate number,
+// like 2.11.0, 3.0.0-beta, 2.11.0-rc2
+type Semver struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+	Pre   string // pre-release, like beta, alpha.1
+	RC    int    // release candidate number, zero means not specified
+}
+
+// ParseSemver parses version text, a leading v is allowed
+func ParseSemver(v string) (Semver, error) {
+	var s Semver
+
+	m := semverCore.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return s, fmt.Errorf("invalid version %q", v)
+	}
+
+	s.Major, _ = strconv.ParseUint(m[1], 10, 64)
+	s.Minor, _ = strconv.ParseUint(m[2], 10, 64)
+	s.Patch, _ = strconv.ParseUint(m[3], 10, 64)
+
+	pre := m[4]
+	if loc := semverRC.FindStringSubmatchIndex(pre); loc != nil {
+		rc, err := strconv.Atoi(pre[loc[2]:loc[3]])
+		if err != nil || rc == 0 {
+			return s, fmt.Errorf("invalid release candidate number in %q", v)
+		}
+		s.RC = rc
+		pre = pre[:loc[0]]
+	}
+
+	if pre != "" {
+		for _, id := range strings.Split(pre, ".") {
+			if id == "" {
+				return s, fmt.Errorf("empty pre-release identifier in %q", v)
+			}
+			if isNumeric(id) && len(id) > 1 && id[0] == '0' {
+				return s, fmt.Errorf("leading zero pre-release identifier in %q", v)
+			}
+		}
+		s.Pre = pre
+	}
+
+	return s, nil
+}
+
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+// IsZero reports whether version was not specified
+func (s Semver) IsZero() bool {
+	return s == Semver{}
+}
+
+// Version release version without candidate number, like 3.0.0-beta
+func (s Semver) Version() string {
+	v := fmt.Sprintf("%d.%d.%d", s.Major, s.Minor, s.Patch)
+	if s.Pre != "" {
+		v = fmt.Sprintf("%s-%s", v, s.Pre)
+	}
+
+	return v
+}
+
+// RCName candidate number suffix, like rc2, empty if not specified
+func (s Semver) RCName() string {
+	if s.RC == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("rc%d", s.RC)
+}
+
+// String full candidate version, like 2.11.0-rc2
+func (s Semver) String() string {
+	if s.RC == 0 {
+		return s.Version()
+	}
+
+	return fmt.Sprintf("%s-%s", s.Version(), s.RCName())
+}
+
+// Compare returns -1, 0, +1 with semantic version precedence,
+// candidate number compares last and a version without it ranks higher
+func (s Semver) Compare(o Semver) int {
+	if c := compareUint(s.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(s.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(s.Patch, o.Patch); c != 0 {
+		return c
+	}
+	if c := comparePre(s.Pre, o.Pre); c != 0 {
+		return c
+	}
+
+	switch {
+	case s.RC == o.RC:
+		return 0
+	case s.RC == 0:
+		return 1
+	case o.RC == 0:
+		return -1
+	}
+
+	return compareUint(uint64(s.RC), uint64(o.RC))
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// comparePre a version without pre-release has higher precedence
+func comparePre(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, bn := isNumeric(as[i]), isNumeric(bs[i])
+		switch {
+		case an && bn:
+			x, _ := strconv.ParseUint(as[i], 10, 64)
+			y, _ := strconv.ParseUint(bs[i], 10, 64)
+			if c := compareUint(x, y); c != 0 {
+				return c
+			}
+		case an:
+			return -1
+		case bn:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return compareUint(uint64(len(as)), uint64(len(bs)))
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    Semver
+		wantErr bool
+	}{
+		{
+			name:    "release",
+			version: "2.11.0",
+			want:    Semver{Major: 2, Minor: 11},
+		},
+		{
+			name:    "pre-release",
+			version: "3.0.0-beta",
+			want:    Semver{Major: 3, Pre: "beta"},
+		},
+		{
+			name:    "release candidate",
+			version: "2.11.0-rc2",
+			want:    Semver{Major: 2, Minor: 11, RC: 2},
+		},
+		{
+			name:    "pre-release candidate",
+			version: "v3.0.0-beta.1-rc1",
+			want:    Semver{Major: 3, Pre: "beta.1", RC: 1},
+		},
+		{
+			name:    "missing patch",
+			version: "2.11",
+			wantErr: true,
+		},
+		{
+			name:    "leading zero",
+			version: "2.011.0",
+			wantErr: true,
+		},
+		{
+			name:    "empty identifier",
+			version: "2.11.0-beta..1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSemver(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSemver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want && !tt.wantErr {
+				t.Errorf("ParseSemver() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemver_Compare(t *testing.T) {
+	ordered := []string{"2.10.4", "2.11.0-alpha", "2.11.0-alpha.1", "2.11.0-beta", "2.11.0-rc1", "2.11.0-rc2", "2.11.0", "2.11.1", "3.0.0-beta"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := ParseSemver(ordered[i-1])
+		b, _ := ParseSemver(ordered[i])
+		if a.Compare(b) >= 0 || b.Compare(a) <= 0 {
+			t.Errorf("expect %s < %s", a, b)
+		}
+	}
+}
+
+func TestCandidate_Naming(t *testing.T) {
+	rc, _ := ParseSemver("3.0.0-beta-rc2")
+	c := &Candidate{
+		pkg:       pkgAPISixDashboard,
+		rc:        rc,
+		pkgPrefix: prefixApache,
+		naming:    dashboardNaming,
+	}
+
+	if got := c.Package(); got != "apisix-dashboard-3.0.0-beta" {
+		t.Errorf("Package() = %s", got)
+	}
+	if got := c.srcArchive(); got != "apache-apisix-dashboard-3.0.0-beta-src.tgz" {
+		t.Errorf("srcArchive() = %s", got)
+	}
+	if got := c.Tag(); got != "v3.0.0-beta" {
+		t.Errorf("Tag() = %s", got)
+	}
+	if got := c.Branch(); got != "release/3.0" {
+		t.Errorf("Branch() = %s", got)
+	}
+	if got := markdownAnchor(c.Anchor()); got != "300-beta" {
+		t.Errorf("markdownAnchor() = %s", got)
+	}
+
+	c.naming.Archive = "-src.zip"
+	if got := c.SrcSha512Link(); got != baseLink+"apisix-dashboard-3.0.0-beta/apache-apisix-dashboard-3.0.0-beta-src.zip.sha512" {
+		t.Errorf("SrcSha512Link() = %s", got)
+	}
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/spf13/cobra"
@@ -32,22 +33,49 @@ func init() {
 	sixer.AddCommand(versionCmd, verboseCmd)
 	sixer.AddCommand(apiSixCmd, dashboardCmd, ingressControllerCmd)
 	sixer.AddCommand(goPluginRunnerCmd)
+	sixer.AddCommand(listCmd, batchCmd, verifyCmd, cacheCmd)
+	sixer.AddCommand(diffCmd)
+}
+
+func init() {
+	cobra.OnInitialize(func() {
+		if err := initTransport(); err != nil {
+			log.Fatalln("Initialize http transport failed:", err)
+		}
+		initCache()
+		initDeadline()
+	})
 }
 
 func init() {
 	globals := sixer.PersistentFlags()
 	BindGlobalFlags(globals)
+	_ = sixer.RegisterFlagCompletionFunc("candidate", completeCandidate)
 
 	BindVerFlags(sixer.Flags())
 }
 
-func sixerPreRun(cmd *cobra.Command, args []string) {
+// sixerPreRunE validate candidate flags, then open workspace; errors return
+// through cobra so that workspace always gets closed
+func sixerPreRunE(cmd *cobra.Command, args []string) error {
+	if err := resolveLatest(cmd); err != nil {
+		return fmt.Errorf("resolve latest release candidate failed: %w", err)
+	}
 	if candidate == "" {
-		log.Fatalln("Please specify release candidate version first")
+		return fmt.Errorf("please specify release candidate version first")
 	}
-	if announcer == "" {
-		log.Fatalln("Please specify release announcer")
+	if _, err := ParseSemver(candidate); err != nil {
+		return fmt.Errorf("please specify valid release candidate version: %w", err)
+	}
+	// released verifies voted artifacts against release KEYS, no announcer
+	if announcer == "" && cmd.Name() != releasedCmd.Name() {
+		return fmt.Errorf("please specify release announcer")
+	}
+
+	if err := openWorkspace(); err != nil {
+		return fmt.Errorf("open workspace failed: %w", err)
 	}
+	return nil
 }
 
 func sixerRun(cmd *cobra.Command, args []string) {
@@ -61,7 +89,10 @@ func sixerRun(cmd *cobra.Command, args []string) {
 }
 
 func main() {
-	if err := sixer.Execute(); err != nil {
+	err := sixer.Execute()
+	cancelRun()
+	closeWorkspace()
+	if err != nil {
 		log.Fatalln("sixer run failed:", err)
 	}
 }
@@ -0,0 +1,80 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestMain tests could record or replay interactions:
+// go test ./... -args -record testdata/cassette
+// go test ./... -args -replay testdata/cassette
+func TestMain(m *testing.M) {
+	flag.StringVar(&recordDir, "record", "", "cassette directory to record HTTP interactions")
+	flag.StringVar(&replayDir, "replay", "", "cassette directory to replay HTTP interactions")
+	flag.Parse()
+
+	if err := initTransport(); err != nil {
+		log.Fatalln("Initialize http transport failed:", err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestSixerPreRunE(t *testing.T) {
+	dir := t.TempDir()
+	workdir = dir
+	defer func() {
+		workdir, candidate, announcer = "", "", ""
+		closeWorkspace()
+	}()
+
+	tests := []struct {
+		name      string
+		cmd       *cobra.Command
+		candidate string
+		announcer string
+		wantErr   bool
+	}{
+		{name: "missing candidate", announcer: "Zeping Bai", wantErr: true},
+		{name: "invalid candidate", candidate: "2.11", announcer: "Zeping Bai", wantErr: true},
+		{name: "missing announcer", candidate: "2.11.0", wantErr: true},
+		{name: "valid", candidate: "2.11.0", announcer: "Zeping Bai"},
+		{name: "released without announcer", cmd: releasedCmd, candidate: "2.11.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidate, announcer = tt.candidate, tt.announcer
+			cmd := tt.cmd
+			if cmd == nil {
+				cmd = dashboardCmd
+			}
+			closeWorkspace()
+			err := sixerPreRunE(cmd, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sixerPreRunE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// invalid flags never open workspace
+			if (workspace != nil) == tt.wantErr {
+				t.Errorf("workspace opened = %v", workspace != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// transport shared by all requests of the run, built from flags
+var transport http.RoundTripper
+
+// TransportOptions configure the shared transport
+type TransportOptions struct {
+	Proxy     string // proxy URL, defaults to HTTPS_PROXY HTTP_PROXY NO_PROXY environment
+	CAFile    string // extra CA bundle in PEM
+	CertFile  string // client certificate in PEM
+	KeyFile   string // client certificate key in PEM
+	Insecure  bool   // skip server certificate verification, only for local mirrors
+	UserAgent string
+}
+
+// NewTransport transport with proxy, TLS and User-Agent options
+func NewTransport(o TransportOptions) (http.RoundTripper, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if o.Proxy != "" {
+		u, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %s: %s", o.Proxy, err)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+
+	tc := &tls.Config{InsecureSkipVerify: o.Insecure}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", o.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	t.TLSClientConfig = tc
+
+	if o.UserAgent == "" {
+		return t, nil
+	}
+
+	return &userAgentTransport{next: t, userAgent: o.UserAgent}, nil
+}
+
+// userAgentTransport set User-Agent if request doesn't have one
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	// RoundTrip should not modify request
+	r := req.Clone(req.Context())
+	r.Header.Set("User-Agent", t.userAgent)
+	return t.next.RoundTrip(r)
+}
+
+// initTransport build shared transport from flags before any command runs
+func initTransport() error {
+	t, err := NewTransport(transportOpts)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case recordDir != "" && replayDir != "":
+		return fmt.Errorf("record and replay are exclusive")
+	case recordDir != "":
+		t, err = NewRecorder(recordDir, t)
+	case replayDir != "":
+		t, err = NewReplayer(replayDir)
+	}
+	if err != nil {
+		return err
+	}
+
+	transport = t
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeTransport func(req *http.Request) (*http.Response, error)
+
+func (f fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewTransport(t *testing.T) {
+	var agent string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent = r.UserAgent()
+	}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    TransportOptions
+		wantErr bool
+	}{
+		{name: "unknown authority", opts: TransportOptions{}, wantErr: true},
+		{name: "custom ca", opts: TransportOptions{CAFile: caFile, UserAgent: "sixer/test"}},
+		{name: "insecure skip verify", opts: TransportOptions{Insecure: true, UserAgent: "sixer/test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, err := NewTransport(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			l := &Linker{}
+			l.SetTransport(rt)
+			if _, err := l.Head(srv.URL); (err != nil) != tt.wantErr {
+				t.Fatalf("Head() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && agent != tt.opts.UserAgent {
+				t.Errorf("User-Agent = %s, want %s", agent, tt.opts.UserAgent)
+			}
+		})
+	}
+}
+
+func TestLinker_SetTransport(t *testing.T) {
+	l := &Linker{}
+	l.SetTransport(fakeTransport(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("KEYS")),
+			Request:    req,
+		}, nil
+	}))
+
+	body, err := l.Get("https://dist.apache.org/repos/dist/release/apisix/KEYS")
+	if err != nil || body != "KEYS" {
+		t.Errorf("Get() = %s, %v", body, err)
+	}
+}
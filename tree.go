@@ -0,0 +1,226 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxKeptSize content of larger files never kept, compared by digest only
+const maxKeptSize = 1 << 20
+
+// A treeFile is a regular file or symbolic link of archive
+type treeFile struct {
+	sum  [sha256.Size]byte
+	size int64
+	link string // target of symbolic link
+	body []byte // content, kept only if asked
+
+	binary bool // NUL found in head of content
+}
+
+// A fileTree collects files of archive by name relative to its single top
+// directory, directories skipped
+type fileTree struct {
+	files map[string]*treeFile
+	keep  func(name string) bool // whether to keep content of entry name
+}
+
+func newFileTree(keep func(name string) bool) *fileTree {
+	return &fileTree{files: map[string]*treeFile{}, keep: keep}
+}
+
+// visit collect entry, an entryVisitor
+func (t *fileTree) visit(hdr *tar.Header, r io.Reader) error {
+	name := entryName(hdr.Name)
+	switch hdr.Typeflag {
+	case tar.TypeSymlink, tar.TypeLink:
+		t.files[name] = &treeFile{link: hdr.Linkname, sum: sha256.Sum256([]byte(hdr.Linkname))}
+	case tar.TypeReg:
+		var body bytes.Buffer
+		h := sha256.New()
+		w := io.Writer(h)
+		kept := t.keep != nil && hdr.Size <= maxKeptSize && t.keep(name)
+		if kept {
+			w = io.MultiWriter(h, &body)
+		}
+		head := make([]byte, sniffSize)
+		m, err := io.ReadFull(r, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		head = head[:m]
+		n, err := io.Copy(w, io.MultiReader(bytes.NewReader(head), r))
+		if err != nil {
+			return err
+		}
+
+		f := &treeFile{size: n, binary: !isText(head)}
+		copy(f.sum[:], h.Sum(nil))
+		if kept {
+			f.body = append([]byte{}, body.Bytes()...)
+		}
+		t.files[name] = f
+	}
+
+	return nil
+}
+
+// stripTop drop single top directory shared by all files, if any
+func (t *fileTree) stripTop() {
+	top := ""
+	for name := range t.files {
+		if !strings.Contains(name, "/") {
+			return
+		}
+		if top == "" {
+			top = topDir(name)
+		} else if topDir(name) != top {
+			return
+		}
+	}
+	if top == "" {
+		return
+	}
+
+	files := make(map[string]*treeFile, len(t.files))
+	for name, f := range t.files {
+		files[strings.TrimPrefix(name, top+"/")] = f
+	}
+	t.files = files
+}
+
+// names of files, sorted
+func (t *fileTree) names() []string {
+	names := make([]string, 0, len(t.files))
+	for name := range t.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// readTree read files of archive, top directory stripped; error tells the
+// archive unreadable or corrupted
+func readTree(filename string, keep func(name string) bool) (*fileTree, error) {
+	t := newFileTree(keep)
+	s, err := scanArchive(filename, nil, []entryVisitor{t.visit})
+	if err != nil {
+		return nil, err
+	}
+	if s.entriesErr != nil {
+		return nil, s.entriesErr
+	}
+
+	t.stripTop()
+	return t, nil
+}
+
+// A treeDiff lists names of files added, removed and modified, sorted
+type treeDiff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// diffTrees compare files of to against from, names ignored are skipped
+func diffTrees(from, to *fileTree, ignored func(name string) bool) treeDiff {
+	var diff treeDiff
+	skip := func(name string) bool {
+		return ignored != nil && ignored(name)
+	}
+
+	for _, name := range to.names() {
+		if skip(name) {
+			continue
+		}
+		f, ok := from.files[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+		case f.sum != to.files[name].sum || f.link != to.files[name].link:
+			diff.Modified = append(diff.Modified, name)
+		}
+	}
+	for _, name := range from.names() {
+		if _, ok := to.files[name]; !ok && !skip(name) {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	return diff
+}
+
+// matchPattern match name against gitattributes or gitignore like pattern:
+// without slash it matches any path component, otherwise the path from root,
+// a matched directory covers everything under it
+func matchPattern(pattern, name string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" {
+		return false
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		if anchored {
+			if ok, _ := path.Match(pattern, strings.Join(parts[:i+1], "/")); ok {
+				return true
+			}
+		} else if ok, _ := path.Match(pattern, parts[i]); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exportIgnored patterns with export-ignore attribute in .gitattributes
+func exportIgnored(gitattributes []byte) []string {
+	var patterns []string
+	for _, line := range strings.Split(string(gitattributes), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			if attr == "export-ignore" || attr == "export-ignore=true" {
+				patterns = append(patterns, fields[0])
+				break
+			}
+		}
+	}
+
+	return patterns
+}
+
+// matchAny whether name matches any of patterns
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if matchPattern(p, name) {
+			return true
+		}
+	}
+
+	return false
+}
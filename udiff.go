@@ -0,0 +1,219 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// diffContext lines of context around changes in hunk
+	diffContext = 3
+	// maxEdits edits beyond which diff gives up, files changed too much
+	maxEdits = 4000
+	// sniffSize head of content looked for NUL telling binary
+	sniffSize = 8000
+)
+
+// A lineOp is a line kept, deleted or inserted by edit script
+type lineOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// splitLines lines of text, without line endings
+func splitLines(text []byte) []string {
+	if len(text) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(string(text), "\n"), "\n")
+}
+
+// diffLines shortest edit script from a to b by Myers' algorithm,
+// false if it needs more than maxEdits edits
+func diffLines(a, b []string) ([]lineOp, bool) {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit > maxEdits {
+		limit = maxEdits
+	}
+
+	// v[k] furthest x on diagonal k, offset by limit+1; trace saves v of each step
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int{}, v[off-d-1:off+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// backtrack edit script from saved steps of diffLines
+func backtrack(trace [][]int, a, b []string) []lineOp {
+	var ops []lineOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		// trace[d] covers diagonals -d-1..d+1
+		at := func(k int) int { return trace[d][k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, lineOp{kind: ' ', line: a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			ops = append(ops, lineOp{kind: '+', line: b[y-1]})
+			y--
+		} else {
+			ops = append(ops, lineOp{kind: '-', line: a[x-1]})
+			x--
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// isText whether content looks like text, without NUL in its head
+func isText(body []byte) bool {
+	head := body
+	if len(head) > sniffSize {
+		head = head[:sniffSize]
+	}
+
+	return bytes.IndexByte(head, 0) < 0
+}
+
+// writeUnified write unified diff of from and to content of name,
+// nothing if identical
+func writeUnified(w io.Writer, name string, from, to []byte) {
+	if !isText(from) || !isText(to) {
+		fmt.Fprintf(w, "Binary files a/%s and b/%s differ\n", name, name)
+		return
+	}
+
+	a, b := splitLines(from), splitLines(to)
+	ops, ok := diffLines(a, b)
+	if !ok {
+		fmt.Fprintf(w, "Files a/%s and b/%s differ, too many changes to show\n", name, name)
+		return
+	}
+
+	header := false
+	for start := 0; start < len(ops); {
+		// next change, hunk starts with context before it
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// hunk ends once more than two contexts of unchanged lines follow
+		last, end := first, first
+		for end < len(ops) && end-last <= 2*diffContext {
+			if ops[end].kind != ' ' {
+				last = end
+			}
+			end++
+		}
+		begin := first - diffContext
+		if begin < start {
+			begin = start
+		}
+		stop := last + 1 + diffContext
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		if !header {
+			fmt.Fprintf(w, "--- a/%s\n+++ b/%s\n", name, name)
+			header = true
+		}
+		writeHunk(w, ops, begin, stop)
+		start = stop
+	}
+}
+
+// writeHunk write ops[begin:stop] as one hunk, line numbers counted from ops head
+func writeHunk(w io.Writer, ops []lineOp, begin, stop int) {
+	aLine, bLine := 1, 1
+	for _, op := range ops[:begin] {
+		if op.kind != '+' {
+			aLine++
+		}
+		if op.kind != '-' {
+			bLine++
+		}
+	}
+
+	aCount, bCount := 0, 0
+	for _, op := range ops[begin:stop] {
+		if op.kind != '+' {
+			aCount++
+		}
+		if op.kind != '-' {
+			bCount++
+		}
+	}
+	// empty range starts before its line, like diff -u
+	if aCount == 0 {
+		aLine--
+	}
+	if bCount == 0 {
+		bLine--
+	}
+
+	fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+	for _, op := range ops[begin:stop] {
+		fmt.Fprintf(w, "%c%s\n", op.kind, op.line)
+	}
+}
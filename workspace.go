@@ -0,0 +1,226 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	lockFilename     = ".sixer.lock"
+	manifestFilename = ".sixer.manifest"
+)
+
+// workspace of the run, opened before command runs
+var workspace *Workspace
+
+// A Workspace is the directory which a run downloads files into,
+// files created by sixer are listed in manifest so that cleaning never
+// touches others, a lock file keeps concurrent runs away
+type Workspace struct {
+	dir  string
+	temp bool // created by sixer, removed as a whole
+
+	mu sync.Mutex
+}
+
+// OpenWorkspace lock dir as workspace, empty dir means a unique temporary one
+func OpenWorkspace(dir string) (*Workspace, error) {
+	w := &Workspace{dir: dir}
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "sixer-")
+		if err != nil {
+			return nil, err
+		}
+		w.dir, w.temp = tmp, true
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	lock, err := os.OpenFile(filepath.Join(w.dir, lockFilename), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		pid, _ := os.ReadFile(filepath.Join(w.dir, lockFilename))
+		return nil, fmt.Errorf("workspace %s locked by process %s, remove %s if it exited",
+			w.dir, strings.TrimSpace(string(pid)), lockFilename)
+	} else if err != nil {
+		return nil, err
+	}
+	defer lock.Close()
+
+	if _, err := fmt.Fprintf(lock, "%d\n", os.Getpid()); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Dir directory of workspace, empty for nil workspace which means current directory
+func (w *Workspace) Dir() string {
+	if w == nil {
+		return ""
+	}
+
+	return w.dir
+}
+
+// Track record file created by sixer
+func (w *Workspace) Track(path string) error {
+	if w == nil {
+		return nil
+	}
+
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, name := range w.tracked() {
+		if name == rel {
+			return nil
+		}
+	}
+
+	f, err := os.OpenFile(filepath.Join(w.dir, manifestFilename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, rel)
+	return err
+}
+
+// Tracked whether file is created by sixer, nil workspace owns no file
+func (w *Workspace) Tracked(path string) bool {
+	if w == nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, name := range w.tracked() {
+		if name == rel {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *Workspace) tracked() []string {
+	f, err := os.Open(filepath.Join(w.dir, manifestFilename))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var names []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if name := strings.TrimSpace(s.Text()); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Clean remove files created by sixer, including ones of earlier kept runs
+func (w *Workspace) Clean() error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, name := range w.tracked() {
+		if err := os.Remove(filepath.Join(w.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Remove(filepath.Join(w.dir, manifestFilename)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Close release lock, remove created files unless keep
+func (w *Workspace) Close(keep bool) error {
+	if w == nil {
+		return nil
+	}
+
+	if keep {
+		return os.Remove(filepath.Join(w.dir, lockFilename))
+	}
+
+	if w.temp {
+		return os.RemoveAll(w.dir)
+	}
+
+	if err := w.Clean(); err != nil {
+		return err
+	}
+
+	return os.Remove(filepath.Join(w.dir, lockFilename))
+}
+
+// openWorkspace open workspace of the run from flags
+func openWorkspace() error {
+	if workspace != nil {
+		return nil
+	}
+
+	w, err := OpenWorkspace(workdir)
+	if err != nil {
+		return err
+	}
+
+	workspace = w
+	return nil
+}
+
+// closeWorkspace close workspace of the run if opened
+func closeWorkspace() {
+	if workspace == nil {
+		return
+	}
+
+	if keep {
+		log.Printf("files kept in %s\n", workspace.Dir())
+	}
+	if err := workspace.Close(keep); err != nil {
+		log.Printf("workspace %s close bad ❌ %s\n", workspace.Dir(), err)
+	}
+	workspace = nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 kwanhur
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	userKey := filepath.Join(dir, keyFilename)
+	if err := os.WriteFile(userKey, []byte("user key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := OpenWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenWorkspace(dir); err == nil {
+		t.Error("workspace shared by concurrent runs expect locked")
+	}
+
+	created := filepath.Join(dir, "apache-apisix-dashboard-2.11.0-src.tgz")
+	if err := ws.Track(created); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(created, []byte("tgz"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ws.Close(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("created file expect removed")
+	}
+	if _, err := os.Stat(userKey); err != nil {
+		t.Error("user key expect kept")
+	}
+
+	// unlocked after close
+	ws, err = OpenWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = ws.Close(true)
+
+	tmp, err := OpenWorkspace("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tmp.Close(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tmp.Dir()); !os.IsNotExist(err) {
+		t.Error("temporary workspace expect removed")
+	}
+}
+
+func TestDist_fetchKeyUserKey(t *testing.T) {
+	dir := t.TempDir()
+	userKey := filepath.Join(dir, keyFilename)
+	body, err := os.ReadFile(writeKeys(t, newSigner(t, "Someone Else")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userKey, body, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := OpenWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// key of another identity in workdir belongs to user, never overwritten
+	d := &Dist{announcer: "Zeping Bai", dir: dir, ws: ws}
+	if err := d.fetchKey(); err == nil {
+		t.Error("fetchKey() over key of user expect error")
+	}
+	if err := ws.Close(false); err != nil {
+		t.Fatal(err)
+	}
+	if kept, err := os.ReadFile(userKey); err != nil || string(kept) != string(body) {
+		t.Errorf("user key expect kept, error = %v", err)
+	}
+}